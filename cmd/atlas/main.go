@@ -3,13 +3,18 @@ package main
 import (
 	"os"
 
+	"github.com/kabilan108/atlas/internal/bitbucket"
 	"github.com/kabilan108/atlas/internal/cli"
 )
 
-var version = "dev"
+var (
+	version = "dev"
+	commit  = ""
+	date    = ""
+)
 
 func main() {
-	if err := cli.Execute(version); err != nil {
-		os.Exit(1)
+	if err := cli.Execute(cli.BuildInfo{Version: version, Commit: commit, Built: date}); err != nil {
+		os.Exit(bitbucket.ExitCodeFromError(err))
 	}
 }
@@ -0,0 +1,154 @@
+package bitbucket
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestSSODetection(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		location string
+		body     string
+		wantSSO  bool
+	}{
+		{"sso message", 401, "", `{"type":"error","error":{"message":"Your account requires SSO login"}}`, true},
+		{"two-step message", 401, "", `{"type":"error","error":{"message":"Invalid credentials","detail":"Two-step verification is enabled for this account"}}`, true},
+		{"sso-like word", 401, "", `{"type":"error","error":{"message":"Missing ssoToken parameter"}}`, false},
+		{"assoc substring", 401, "", `{"type":"error","error":{"message":"Account is not associated with this workspace"}}`, false},
+		{"sso outside error object", 401, "", `<html>sso login</html>`, false},
+		{"identity provider redirect", 302, "https://id.atlassian.com/login?continue=x", "", true},
+		{"saml redirect", 302, "https://idp.example.com/saml2/sso?SAMLRequest=abc", "", true},
+		{"other redirect", 302, "https://bitbucket.org/some/page", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.location != "" {
+					w.Header().Set("Location", tt.location)
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			})
+			c, _ := newTestClient(t, handler, WithNoCache(true))
+
+			_, err := c.get("/user")
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("err = %v, want an APIError", err)
+			}
+			isSSO := apiErr.Message == NewSSOError().Message
+			if isSSO != tt.wantSSO {
+				t.Errorf("SSO = %v (%q), want %v", isSSO, apiErr.Message, tt.wantSSO)
+			}
+		})
+	}
+}
+
+func TestExpiredTokenDetection(t *testing.T) {
+	tests := []struct {
+		name        string
+		challenge   string
+		body        string
+		wantExpired bool
+	}{
+		{"invalid_token challenge", `Bearer error="invalid_token"`, "", true},
+		{"app password expired", "", `{"type":"error","error":{"message":"App password expired"}}`, true},
+		{"token revoked", "", `{"type":"error","error":{"message":"Access token has been revoked"}}`, true},
+		{"account password expired", "", `{"type":"error","error":{"message":"Password expired"}}`, false},
+		{"session expired", "", `{"type":"error","error":{"message":"Session expired, please log in again"}}`, false},
+		{"expired outside error object", "", `token expired`, false},
+		{"plain invalid credentials", `Basic realm="Bitbucket.org HTTP"`, `{"type":"error","error":{"message":"Invalid credentials"}}`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.challenge != "" {
+					w.Header().Set("WWW-Authenticate", tt.challenge)
+				}
+				w.WriteHeader(http.StatusUnauthorized)
+				w.Write([]byte(tt.body))
+			})
+			c, _ := newTestClient(t, handler, WithNoCache(true))
+
+			_, err := c.get("/user")
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("err = %v, want an APIError", err)
+			}
+			expired := apiErr.Message == NewExpiredTokenError().Message
+			if expired != tt.wantExpired {
+				t.Errorf("expired = %v (%q), want %v", expired, apiErr.Message, tt.wantExpired)
+			}
+			if apiErr.ExitCode() != ExitAuthError {
+				t.Errorf("exit code = %d, want %d", apiErr.ExitCode(), ExitAuthError)
+			}
+		})
+	}
+}
+
+func TestGetCurrentUserWithScopes(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   []string
+	}{
+		{"scopes reported", "account, repository:write,pullrequest", []string{"account", "repository:write", "pullrequest"}},
+		{"scopes absent", "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				if tt.header != "" {
+					w.Header().Set("X-OAuth-Scopes", tt.header)
+				}
+				w.Write([]byte(`{"uuid":"{u}","username":"alice","display_name":"Alice"}`))
+			})
+			c, _ := newTestClient(t, handler, WithNoCache(true))
+
+			user, scopes, err := c.GetCurrentUserWithScopes()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if user.Username != "alice" {
+				t.Errorf("username = %q, want alice", user.Username)
+			}
+			if !reflect.DeepEqual(scopes, tt.want) {
+				t.Errorf("scopes = %q, want %q", scopes, tt.want)
+			}
+			if calls != 1 {
+				t.Errorf("made %d requests to /user, want 1", calls)
+			}
+		})
+	}
+}
+
+func TestMissingScopes(t *testing.T) {
+	tests := []struct {
+		name    string
+		granted []string
+		want    []string
+	}{
+		{"all granted", []string{"account", "repository", "pullrequest"}, nil},
+		{"write implies read", []string{"account:write", "repository:admin", "pullrequest:write"}, nil},
+		{"missing pullrequest", []string{"account", "repository"}, []string{"pullrequest"}},
+		{"unrelated scopes", []string{"snippet", "webhook"}, []string{"account", "repository", "pullrequest"}},
+		{"none", nil, []string{"account", "repository", "pullrequest"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MissingScopes(tt.granted); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MissingScopes(%v) = %v, want %v", tt.granted, got, tt.want)
+			}
+		})
+	}
+}
@@ -18,19 +18,20 @@ type Cache struct {
 
 type cacheEntry struct {
 	Data      json.RawMessage `json:"data"`
+	ETag      string          `json:"etag,omitempty"`
 	CachedAt  time.Time       `json:"cached_at"`
 	ExpiresAt time.Time       `json:"expires_at"`
 }
 
 func NewCache() (*Cache, error) {
-	cacheDir, err := cacheDir()
+	cacheDir, err := CacheDir()
 	if err != nil {
 		return nil, err
 	}
 	return &Cache{dir: cacheDir, ttl: defaultTTL}, nil
 }
 
-func cacheDir() (string, error) {
+func CacheDir() (string, error) {
 	dir := os.Getenv("XDG_CACHE_HOME")
 	if dir == "" {
 		home, err := os.UserHomeDir()
@@ -44,6 +45,41 @@ func cacheDir() (string, error) {
 
 func (c *Cache) Get(key string) ([]byte, bool) {
 	path := c.keyPath(key)
+	entry, ok := c.read(path)
+	if !ok {
+		return nil, false
+	}
+
+	if time.Now().After(entry.ExpiresAt) {
+		// Expired entries with an ETag are kept for conditional requests
+		if entry.ETag == "" {
+			os.Remove(path)
+		}
+		return nil, false
+	}
+
+	return entry.Data, true
+}
+
+// GetAny returns the entry for key whatever its age or ETag, and never
+// removes it. Offline mode reads through it so it can't evict what it serves.
+func (c *Cache) GetAny(key string) ([]byte, bool) {
+	entry, ok := c.read(c.keyPath(key))
+	if !ok {
+		return nil, false
+	}
+	return entry.Data, true
+}
+
+func (c *Cache) GetStale(key string) ([]byte, string, bool) {
+	entry, ok := c.read(c.keyPath(key))
+	if !ok || entry.ETag == "" {
+		return nil, "", false
+	}
+	return entry.Data, entry.ETag, true
+}
+
+func (c *Cache) read(path string) (*cacheEntry, bool) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, false
@@ -53,16 +89,22 @@ func (c *Cache) Get(key string) ([]byte, bool) {
 	if err := json.Unmarshal(data, &entry); err != nil {
 		return nil, false
 	}
+	return &entry, true
+}
 
-	if time.Now().After(entry.ExpiresAt) {
-		os.Remove(path)
-		return nil, false
-	}
+func (c *Cache) Set(key string, data []byte) error {
+	return c.SetWithETag(key, data, "")
+}
 
-	return entry.Data, true
+func (c *Cache) SetWithETag(key string, data []byte, etag string) error {
+	return c.write(key, data, etag, c.ttl)
 }
 
-func (c *Cache) Set(key string, data []byte) error {
+func (c *Cache) SetWithTTL(key string, data []byte, ttl time.Duration) error {
+	return c.write(key, data, "", ttl)
+}
+
+func (c *Cache) write(key string, data []byte, etag string, ttl time.Duration) error {
 	if err := os.MkdirAll(c.dir, 0700); err != nil {
 		return err
 	}
@@ -70,8 +112,9 @@ func (c *Cache) Set(key string, data []byte) error {
 	now := time.Now()
 	entry := cacheEntry{
 		Data:      data,
+		ETag:      etag,
 		CachedAt:  now,
-		ExpiresAt: now.Add(c.ttl),
+		ExpiresAt: now.Add(ttl),
 	}
 
 	encoded, err := json.Marshal(entry)
@@ -0,0 +1,164 @@
+package bitbucket
+
+import (
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestConditionalRevalidation(t *testing.T) {
+	tests := []struct {
+		name        string
+		cachedETag  string
+		status      int
+		respETag    string
+		body        string
+		wantBody    string
+		wantIfMatch string
+		wantETag    string
+	}{
+		{"not modified serves cache", `"v1"`, http.StatusNotModified, "", "", `{"cached":true}`, `"v1"`, `"v1"`},
+		{"changed replaces cache", `"v1"`, http.StatusOK, `"v2"`, `{"fresh":true}`, `{"fresh":true}`, `"v1"`, `"v2"`},
+		{"no etag refetches", "", http.StatusOK, `"v1"`, `{"fresh":true}`, `{"fresh":true}`, "", `"v1"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ifNoneMatch string
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				ifNoneMatch = r.Header.Get("If-None-Match")
+				if tt.respETag != "" {
+					w.Header().Set("ETag", tt.respETag)
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			})
+			c, srv := newTestClient(t, handler)
+			url := srv.URL + "/repositories/ws/repo"
+			if err := c.cache.write(url, []byte(`{"cached":true}`), tt.cachedETag, -time.Minute); err != nil {
+				t.Fatal(err)
+			}
+
+			data, err := c.get("/repositories/ws/repo")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.wantBody {
+				t.Errorf("body = %s, want %s", data, tt.wantBody)
+			}
+			if ifNoneMatch != tt.wantIfMatch {
+				t.Errorf("If-None-Match = %q, want %q", ifNoneMatch, tt.wantIfMatch)
+			}
+
+			// Whatever was served is now fresh again under the newest ETag.
+			cached, ok := c.cache.Get(url)
+			if !ok || string(cached) != tt.wantBody {
+				t.Errorf("cache after revalidation = %s, %v; want a live %s", cached, ok, tt.wantBody)
+			}
+			if _, etag, _ := c.cache.GetStale(url); etag != tt.wantETag {
+				t.Errorf("stored ETag = %q, want %q", etag, tt.wantETag)
+			}
+		})
+	}
+}
+
+func TestOfflineServesExpiredCacheEntries(t *testing.T) {
+	var calls atomic.Int32
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Write([]byte(`{"uuid":"{1}"}`))
+	})
+
+	tests := []struct {
+		name string
+		etag string
+	}{
+		{"without etag", ""},
+		{"with etag", `"v1"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, srv := newTestClient(t, handler, WithOffline(true))
+			url := srv.URL + "/repositories/ws/repo"
+			if err := c.cache.write(url, []byte(`{"cached":true}`), tt.etag, -time.Minute); err != nil {
+				t.Fatal(err)
+			}
+
+			for i := 0; i < 2; i++ {
+				data, err := c.get("/repositories/ws/repo")
+				if err != nil {
+					t.Fatalf("read %d: %v", i+1, err)
+				}
+				if string(data) != `{"cached":true}` {
+					t.Errorf("read %d = %s, want the cached body", i+1, data)
+				}
+			}
+			if calls.Load() != 0 {
+				t.Errorf("offline client made %d requests", calls.Load())
+			}
+		})
+	}
+}
+
+func TestOfflineUncachedReadFails(t *testing.T) {
+	c, _ := newTestClient(t, http.NotFoundHandler(), WithOffline(true))
+
+	_, err := c.get("/repositories/ws/missing")
+	if !errors.Is(err, ErrOffline) {
+		t.Fatalf("err = %v, want ErrOffline", err)
+	}
+}
+
+func TestGetMainBranchCachedLonger(t *testing.T) {
+	tests := []struct {
+		name      string
+		noCache   bool
+		wantCalls int32
+	}{
+		{"cached past the default ttl", false, 1},
+		{"no cache refetches", true, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+				w.Write([]byte(`{"slug":"repo","mainbranch":{"name":"develop"}}`))
+			})
+			c, _ := newTestClient(t, handler, WithNoCache(tt.noCache))
+			// Ordinary responses expire immediately; only the main branch
+			// entry's own TTL can spare the second request.
+			c.cache.ttl = -time.Minute
+
+			for range 2 {
+				name, err := c.GetMainBranch("ws", "repo")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if name != "develop" {
+					t.Errorf("main branch = %q, want develop", name)
+				}
+			}
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Errorf("requests = %d, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestGetMainBranchMissing(t *testing.T) {
+	c, _ := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"slug":"repo"}`))
+	}))
+
+	if _, err := c.GetMainBranch("ws", "repo"); err == nil {
+		t.Fatal("expected an error for a repository without a main branch")
+	}
+	if _, ok := c.cache.Get("mainbranch:ws/repo"); ok {
+		t.Error("missing main branch was cached")
+	}
+}
@@ -2,27 +2,89 @@ package bitbucket
 
 import (
 	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
 	"net/http"
+	"net/url"
+	"regexp"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/kabilan108/atlas/internal/config"
 )
 
-const baseURL = "https://api.bitbucket.org/2.0"
+var RequiredScopes = []string{"account", "repository", "pullrequest"}
+
+const (
+	defaultBaseURL = "https://api.bitbucket.org/2.0"
+	maxRetries     = 3
+
+	maxCommitSearchPages = 20
+	defaultConcurrency   = 4
+
+	defaultMaxIdleConnsPerHost = 16
+	defaultMaxConnsPerHost     = 32
+	defaultIdleConnTimeout     = 90 * time.Second
+
+	mainBranchTTL  = 24 * time.Hour
+	currentUserTTL = 24 * time.Hour
+
+	// Below this share of the hourly budget the client paces its requests
+	// instead of running into 429s.
+	throttleRatio = 0.1
+	throttleDelay = 500 * time.Millisecond
+)
 
 type Client struct {
-	httpClient *http.Client
-	username   string
-	password   string
-	cache      *Cache
-	noCache    bool
-	retry      bool
+	ctx            context.Context
+	httpClient     *http.Client
+	baseURL        string
+	workspace      string
+	username       string
+	password       string
+	cache          *Cache
+	noCache        bool
+	offline        bool
+	retry          bool
+	progress       func(done, total int)
+	retryStats     func(RetryStats)
+	observer       func(RequestEvent)
+	concurrency    int
+	maxPages       int
+	pageCapWarning func(endpoint string, maxPages int)
+	dryRun         io.Writer
+
+	rateLimit     atomic.Int64
+	rateRemaining atomic.Int64
+}
+
+type RetryStats struct {
+	Method      string
+	URL         string
+	Attempts    int
+	Backoff     time.Duration
+	RateLimited bool
+}
+
+// RequestEvent describes a single HTTP attempt. Final is set on the attempt
+// whose outcome is returned to the caller; earlier attempts were retried.
+type RequestEvent struct {
+	Method  string
+	URL     string
+	Status  int
+	Attempt int
+	Latency time.Duration
+	Err     error
+	Final   bool
 }
 
 type ClientOption func(*Client)
@@ -33,77 +95,311 @@ func WithNoCache(noCache bool) ClientOption {
 	}
 }
 
+// WithOffline serves reads only from the disk cache, including expired
+// entries, and fails every request that would reach the network.
+func WithOffline(offline bool) ClientOption {
+	return func(c *Client) {
+		c.offline = offline
+	}
+}
+
+func WithContext(ctx context.Context) ClientOption {
+	return func(c *Client) {
+		c.ctx = ctx
+	}
+}
+
+func WithProgress(progress func(done, total int)) ClientOption {
+	return func(c *Client) {
+		c.progress = progress
+	}
+}
+
 func WithRetry(retry bool) ClientOption {
 	return func(c *Client) {
 		c.retry = retry
 	}
 }
 
+func WithConcurrency(n int) ClientOption {
+	return func(c *Client) {
+		c.concurrency = n
+	}
+}
+
+func WithConnectionPool(maxIdlePerHost, maxPerHost int) ClientOption {
+	return func(c *Client) {
+		if t, ok := c.httpClient.Transport.(*http.Transport); ok {
+			t.MaxIdleConnsPerHost = maxIdlePerHost
+			t.MaxConnsPerHost = maxPerHost
+		}
+	}
+}
+
+func WithDryRun(w io.Writer) ClientOption {
+	return func(c *Client) {
+		c.dryRun = w
+	}
+}
+
+func WithRetryStats(report func(RetryStats)) ClientOption {
+	return func(c *Client) {
+		c.retryStats = report
+	}
+}
+
+// WithObserver registers a callback fired after every HTTP attempt. It runs
+// synchronously on the request path, so it must return quickly and must not
+// call back into the client.
+func WithObserver(observe func(RequestEvent)) ClientOption {
+	return func(c *Client) {
+		c.observer = observe
+	}
+}
+
+// WithWorkspace selects the workspace whose credential override, if any, the
+// client authenticates with. An empty slug keeps the configured workspace.
+func WithWorkspace(workspace string) ClientOption {
+	return func(c *Client) {
+		if workspace != "" {
+			c.workspace = workspace
+		}
+	}
+}
+
+// WithMaxPages caps how many pages any paginated listing fetches. Zero
+// removes the cap.
+func WithMaxPages(n int) ClientOption {
+	return func(c *Client) {
+		c.maxPages = n
+	}
+}
+
+func WithPageCapWarning(warn func(endpoint string, maxPages int)) ClientOption {
+	return func(c *Client) {
+		c.pageCapWarning = warn
+	}
+}
+
+// WithBaseURL points the client at another API root, such as a test server.
+func WithBaseURL(baseURL string) ClientOption {
+	return func(c *Client) {
+		c.baseURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
 func NewClient(opts ...ClientOption) (*Client, error) {
 	cfg, err := config.Load()
 	if err != nil {
 		return nil, fmt.Errorf("failed to load config: %w", err)
 	}
 
-	if cfg.Username == "" || cfg.AppPassword == "" {
-		return nil, NewAuthError(401, "missing credentials in config")
-	}
-
 	cache, err := NewCache()
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize cache: %w", err)
 	}
 
 	c := &Client{
-		httpClient: &http.Client{Timeout: 30 * time.Second},
-		username:   cfg.Username,
-		password:   cfg.AppPassword,
-		cache:      cache,
+		ctx:         context.Background(),
+		httpClient:  &http.Client{Timeout: 30 * time.Second, Transport: newTransport(), CheckRedirect: stayOnAPIHost},
+		baseURL:     defaultBaseURL,
+		workspace:   cfg.Workspace,
+		cache:       cache,
+		concurrency: defaultConcurrency,
 	}
 
 	for _, opt := range opts {
 		opt(c)
 	}
 
+	c.username, c.password = cfg.CredentialsFor(c.workspace)
+	if c.username == "" || c.password == "" {
+		return nil, NewAuthError(401, "missing credentials in config")
+	}
+
+	// Keep enough idle connections around that concurrent fetches reuse them
+	if t, ok := c.httpClient.Transport.(*http.Transport); ok {
+		if t.MaxIdleConnsPerHost < c.concurrency {
+			t.MaxIdleConnsPerHost = c.concurrency
+		}
+		if t.MaxConnsPerHost > 0 && t.MaxConnsPerHost < c.concurrency {
+			t.MaxConnsPerHost = c.concurrency
+		}
+	}
+
 	return c, nil
 }
 
+// Redirects off the API host (e.g. to an SSO identity provider) are returned
+// as-is so checkResponse can explain them instead of parsing a login page.
+func stayOnAPIHost(req *http.Request, via []*http.Request) error {
+	if len(via) >= 10 {
+		return fmt.Errorf("stopped after 10 redirects")
+	}
+	if req.URL.Host != via[0].URL.Host {
+		return http.ErrUseLastResponse
+	}
+	return nil
+}
+
+func newTransport() *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.MaxIdleConnsPerHost = defaultMaxIdleConnsPerHost
+	t.MaxConnsPerHost = defaultMaxConnsPerHost
+	t.IdleConnTimeout = defaultIdleConnTimeout
+	return t
+}
+
 func (c *Client) do(req *http.Request) (*http.Response, error) {
-	req.SetBasicAuth(c.username, c.password)
-	req.Header.Set("Accept", "application/json")
+	if c.dryRun != nil && req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return nil, c.writeDryRun(req)
+	}
+	if c.offline {
+		return nil, fmt.Errorf("%w: %s %s", ErrOffline, req.Method, req.URL)
+	}
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, err
+	req.SetBasicAuth(c.username, c.password)
+	if req.Header.Get("Accept") == "" {
+		req.Header.Set("Accept", "application/json")
 	}
 
-	if resp.StatusCode == 429 && c.retry {
-		resetTime := parseRateLimitReset(resp.Header)
+	retryable := c.retry && isRetryable(req)
+	stats := RetryStats{Method: req.Method, URL: req.URL.String()}
+	defer c.reportRetryStats(&stats)
+
+	for attempt := 0; ; attempt++ {
+		if c.Throttled() {
+			select {
+			case <-time.After(throttleDelay):
+			case <-c.ctx.Done():
+				return nil, c.ctx.Err()
+			}
+		}
+
+		stats.Attempts++
+		start := time.Now()
+		resp, err := c.httpClient.Do(req)
+		event := RequestEvent{Method: req.Method, URL: stats.URL, Attempt: attempt + 1, Latency: time.Since(start), Err: err}
+		if err != nil {
+			event.Final = true
+			c.observe(event)
+			return nil, err
+		}
+		event.Status = resp.StatusCode
+		c.recordRateLimit(resp.Header)
+
+		if !retryable || attempt >= maxRetries || (resp.StatusCode != 429 && resp.StatusCode < 500) {
+			event.Final = true
+			c.observe(event)
+			return resp, nil
+		}
+		c.observe(event)
+
+		waitDuration := time.Duration(1<<attempt) * time.Second
+		if resp.StatusCode == 429 {
+			waitDuration = time.Until(parseRateLimitReset(resp.Header))
+			stats.RateLimited = true
+		}
 		resp.Body.Close()
 
-		waitDuration := time.Until(resetTime)
 		if waitDuration > 0 {
-			time.Sleep(waitDuration)
+			start := time.Now()
+			select {
+			case <-time.After(waitDuration):
+			case <-c.ctx.Done():
+				stats.Backoff += time.Since(start)
+				return nil, c.ctx.Err()
+			}
+			stats.Backoff += time.Since(start)
+		}
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
 		}
-		return c.httpClient.Do(req)
 	}
+}
+
+func (c *Client) writeDryRun(req *http.Request) error {
+	fmt.Fprintf(c.dryRun, "%s %s\n", req.Method, req.URL)
+	if req.GetBody == nil {
+		return ErrDryRun
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+
+	if strings.HasPrefix(req.Header.Get("Content-Type"), "application/json") {
+		var pretty bytes.Buffer
+		if json.Indent(&pretty, data, "", "  ") == nil {
+			data = pretty.Bytes()
+		}
+		fmt.Fprintf(c.dryRun, "%s\n", data)
+	} else if len(data) > 0 {
+		fmt.Fprintf(c.dryRun, "<%s body, %d bytes>\n", req.Header.Get("Content-Type"), len(data))
+	}
+	return ErrDryRun
+}
+
+func (c *Client) observe(event RequestEvent) {
+	if c.observer != nil {
+		c.observer(event)
+	}
+}
+
+func (c *Client) reportRetryStats(stats *RetryStats) {
+	if c.retryStats != nil && stats.Attempts > 1 {
+		c.retryStats(*stats)
+	}
+}
+
+func isRetryable(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	allowed, _ := req.Context().Value(allowRetryKey{}).(bool)
+	return allowed
+}
+
+type allowRetryKey struct{}
 
-	return resp, nil
+func AllowRetry(ctx context.Context) context.Context {
+	return context.WithValue(ctx, allowRetryKey{}, true)
 }
 
 func (c *Client) get(path string) ([]byte, error) {
-	url := baseURL + path
+	url := c.baseURL + path
 
+	var staleData []byte
+	var etag string
 	if !c.noCache {
-		if data, ok := c.cache.Get(url); ok {
+		if data, ok := c.cached(url); ok {
 			return data, nil
 		}
+		if !c.offline {
+			staleData, etag, _ = c.cache.GetStale(url)
+		}
 	}
 
-	req, err := http.NewRequest(http.MethodGet, url, nil)
+	req, err := http.NewRequestWithContext(c.ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, err
 	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
 
 	resp, err := c.do(req)
 	if err != nil {
@@ -111,6 +407,11 @@ func (c *Client) get(path string) ([]byte, error) {
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotModified && staleData != nil {
+		c.cache.SetWithETag(url, staleData, etag)
+		return staleData, nil
+	}
+
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, err
@@ -121,23 +422,97 @@ func (c *Client) get(path string) ([]byte, error) {
 	}
 
 	if !c.noCache {
-		c.cache.Set(url, body)
+		c.cache.SetWithETag(url, body, resp.Header.Get("ETag"))
+	}
+
+	return body, nil
+}
+
+// cached reads a live cache entry, or any entry at all when offline: there is
+// nothing fresher to fall back on, and expired entries must not be evicted.
+func (c *Client) cached(key string) ([]byte, bool) {
+	if c.offline {
+		return c.cache.GetAny(key)
+	}
+	return c.cache.Get(key)
+}
+
+func (c *Client) getUncached(path string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(c.ctx, http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, err
 	}
+	defer resp.Body.Close()
 
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkResponse(resp, body); err != nil {
+		return nil, err
+	}
 	return body, nil
 }
 
 func (c *Client) getRaw(path string) ([]byte, error) {
-	url := baseURL + path
+	body, err := c.getStream(path)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	return io.ReadAll(body)
+}
 
-	req, err := http.NewRequest(http.MethodGet, url, nil)
+func (c *Client) getStream(path string) (io.ReadCloser, error) {
+	url := c.baseURL + path
+
+	req, err := http.NewRequestWithContext(c.ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	req.SetBasicAuth(c.username, c.password)
+	// Diffs, patches and raw files are not JSON
+	req.Header.Set("Accept", "*/*")
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		return nil, checkResponse(resp, body)
+	}
+
+	return resp.Body, nil
+}
+
+func (c *Client) sendJSON(method, path string, payload any) ([]byte, error) {
+	url := c.baseURL + path
+
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request body: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(c.ctx, method, url, bytes.NewReader(encoded))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -152,6 +527,10 @@ func (c *Client) getRaw(path string) ([]byte, error) {
 		return nil, err
 	}
 
+	if !c.noCache {
+		c.cache.Delete(url)
+	}
+
 	return body, nil
 }
 
@@ -160,8 +539,26 @@ func checkResponse(resp *http.Response, body []byte) error {
 		return nil
 	}
 
+	if resp.StatusCode >= 300 && resp.StatusCode < 400 {
+		location := resp.Header.Get("Location")
+		if isIdentityProviderURL(location) {
+			return NewSSOError()
+		}
+		return &APIError{
+			StatusCode: resp.StatusCode,
+			Message:    fmt.Sprintf("unexpected redirect to %s", location),
+			Resource:   "api",
+		}
+	}
+
 	switch resp.StatusCode {
 	case 401:
+		if isSSORequired(body) {
+			return NewSSOError()
+		}
+		if isExpiredToken(resp.Header, body) {
+			return NewExpiredTokenError()
+		}
 		return NewAuthError(401, "invalid credentials")
 	case 403:
 		return NewAuthError(403, "access denied")
@@ -182,7 +579,91 @@ func checkResponse(resp *http.Response, body []byte) error {
 	}
 }
 
+// isSSORequired matches the message Bitbucket puts in a 401's error object
+// when an account must log in through SSO or two-step verification.
+func isSSORequired(body []byte) bool {
+	return ssoMessagePattern.MatchString(apiErrorMessage(body))
+}
+
+var ssoMessagePattern = regexp.MustCompile(`\b(sso|single sign-on|two-step verification|2fa)\b`)
+
+// isIdentityProviderURL reports whether a redirect leads to an Atlassian or
+// SAML login rather than somewhere else on the API.
+func isIdentityProviderURL(location string) bool {
+	u, err := url.Parse(location)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	if host == "id.atlassian.com" || strings.HasSuffix(host, ".id.atlassian.com") {
+		return true
+	}
+	return strings.Contains(strings.ToLower(u.Path), "/saml") || u.Query().Has("SAMLRequest")
+}
+
+// apiErrorMessage extracts error.message and error.detail from a Bitbucket
+// error body, lowercased. Other bodies (HTML pages, plain text) yield "".
+func apiErrorMessage(body []byte) string {
+	var payload struct {
+		Error struct {
+			Message string `json:"message"`
+			Detail  string `json:"detail"`
+		} `json:"error"`
+	}
+	if json.Unmarshal(body, &payload) != nil {
+		return ""
+	}
+	return strings.ToLower(strings.TrimSpace(payload.Error.Message + " " + payload.Error.Detail))
+}
+
+// isExpiredToken reports a credential that was valid but has lapsed: an OAuth
+// style invalid_token challenge, or an error message about an expired or
+// revoked app password or token. Other "expired" messages (an expired account
+// password or session) fall through to the generic credentials error.
+func isExpiredToken(header http.Header, body []byte) bool {
+	if strings.Contains(header.Get("WWW-Authenticate"), `error="invalid_token"`) {
+		return true
+	}
+	return expiredTokenPattern.MatchString(apiErrorMessage(body))
+}
+
+var expiredTokenPattern = regexp.MustCompile(`\b(app password|api token|access token|token)\b[^.]*\b(expired|revoked)\b`)
+
+// RateLimit returns the request budget reported by the most recent response.
+// ok is false until Bitbucket has sent X-RateLimit-* headers.
+func (c *Client) RateLimit() (remaining, limit int, ok bool) {
+	l := c.rateLimit.Load()
+	if l <= 0 {
+		return 0, 0, false
+	}
+	return int(c.rateRemaining.Load()), int(l), true
+}
+
+// Throttled reports whether the remaining budget is low enough that requests
+// are being paced.
+func (c *Client) Throttled() bool {
+	remaining, limit, ok := c.RateLimit()
+	return ok && float64(remaining) < float64(limit)*throttleRatio
+}
+
+func (c *Client) recordRateLimit(header http.Header) {
+	limit, err := strconv.Atoi(header.Get("X-RateLimit-Limit"))
+	if err != nil || limit <= 0 {
+		return
+	}
+	remaining, err := strconv.Atoi(header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return
+	}
+	c.rateRemaining.Store(int64(remaining))
+	c.rateLimit.Store(int64(limit))
+}
+
 func parseRateLimitReset(header http.Header) time.Time {
+	if retryAfter, err := strconv.Atoi(header.Get("Retry-After")); err == nil && retryAfter >= 0 {
+		return time.Now().Add(time.Duration(retryAfter) * time.Second)
+	}
+
 	resetStr := header.Get("X-RateLimit-Reset")
 	if resetStr == "" {
 		return time.Now().Add(60 * time.Second)
@@ -203,8 +684,21 @@ func extractResource(path string) string {
 	return path
 }
 
+// GetCurrentUser returns the authenticated user. The identity is cached on
+// disk under a hash of the credentials rather than the URL, so it survives
+// across runs but is never served for a different app password.
 func (c *Client) GetCurrentUser() (*User, error) {
-	data, err := c.get("/user")
+	key := "user:" + c.credentialHash()
+	if !c.noCache {
+		if data, ok := c.cached(key); ok {
+			var user User
+			if err := json.Unmarshal(data, &user); err == nil && user.UUID != "" {
+				return &user, nil
+			}
+		}
+	}
+
+	data, err := c.getUncached("/user")
 	if err != nil {
 		return nil, err
 	}
@@ -214,15 +708,168 @@ func (c *Client) GetCurrentUser() (*User, error) {
 		return nil, fmt.Errorf("failed to parse user response: %w", err)
 	}
 
+	if !c.noCache {
+		c.cache.SetWithTTL(key, data, currentUserTTL)
+	}
 	return &user, nil
 }
 
+func (c *Client) credentialHash() string {
+	sum := sha256.Sum256([]byte(c.username + "\x00" + c.password))
+	return hex.EncodeToString(sum[:8])
+}
+
+// GetCurrentUserWithScopes fetches /user once, bypassing the cache, and also
+// returns the granted scopes Bitbucket reports in the X-OAuth-Scopes header.
+// A nil scopes result means the header was absent and the scopes are unknown.
+func (c *Client) GetCurrentUserWithScopes() (*User, []string, error) {
+	req, err := http.NewRequestWithContext(c.ctx, http.MethodGet, c.baseURL+"/user", nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := checkResponse(resp, body); err != nil {
+		return nil, nil, err
+	}
+
+	var user User
+	if err := json.Unmarshal(body, &user); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse user response: %w", err)
+	}
+
+	var scopes []string
+	for _, scope := range strings.Split(resp.Header.Get("X-OAuth-Scopes"), ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			scopes = append(scopes, scope)
+		}
+	}
+	return &user, scopes, nil
+}
+
+func MissingScopes(granted []string) []string {
+	have := make(map[string]bool)
+	for _, scope := range granted {
+		name, _, _ := strings.Cut(scope, ":")
+		have[name] = true
+	}
+
+	var missing []string
+	for _, scope := range RequiredScopes {
+		if !have[scope] {
+			missing = append(missing, scope)
+		}
+	}
+	return missing
+}
+
+// IsSelfAlias reports whether name is one of the spellings that stand for the
+// authenticated user.
+func IsSelfAlias(name string) bool {
+	return name == "me" || name == "@me"
+}
+
+func (c *Client) GetUser(username string) (*User, error) {
+	data, err := c.get(fmt.Sprintf("/users/%s", username))
+	if err != nil {
+		return nil, err
+	}
+
+	var user User
+	if err := json.Unmarshal(data, &user); err != nil {
+		return nil, fmt.Errorf("failed to parse user response: %w", err)
+	}
+
+	return &user, nil
+}
+
+func (c *Client) ListWorkspaces() ([]Workspace, error) {
+	var workspaces []Workspace
+	path := "/workspaces"
+
+	pages := 0
+	for path != "" {
+		data, err := c.get(path)
+		if err != nil {
+			return nil, err
+		}
+
+		var page PaginatedResponse[Workspace]
+		if err := json.Unmarshal(data, &page); err != nil {
+			return nil, fmt.Errorf("failed to parse workspaces response: %w", err)
+		}
+
+		workspaces = append(workspaces, page.Values...)
+		path = c.nextPath(path, page.Next, &pages)
+	}
+
+	return workspaces, nil
+}
+
+func (c *Client) GetRepository(workspace, repo string) (*Repository, error) {
+	data, err := c.get(fmt.Sprintf("/repositories/%s/%s", workspace, repo))
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, NewNotFoundError("repository", workspace+"/"+repo)
+		}
+		return nil, err
+	}
+
+	var repository Repository
+	if err := json.Unmarshal(data, &repository); err != nil {
+		return nil, fmt.Errorf("failed to parse repository response: %w", err)
+	}
+
+	return &repository, nil
+}
+
+// The main branch rarely changes, so it is cached far longer than other
+// responses under its own key.
+func (c *Client) GetMainBranch(workspace, repo string) (string, error) {
+	key := fmt.Sprintf("mainbranch:%s/%s", workspace, repo)
+	if !c.noCache {
+		if data, ok := c.cached(key); ok {
+			var name string
+			if err := json.Unmarshal(data, &name); err == nil && name != "" {
+				return name, nil
+			}
+		}
+	}
+
+	repository, err := c.GetRepository(workspace, repo)
+	if err != nil {
+		return "", err
+	}
+	if repository.MainBranch == nil || repository.MainBranch.Name == "" {
+		return "", fmt.Errorf("repository %s/%s has no main branch", workspace, repo)
+	}
+
+	if !c.noCache {
+		encoded, _ := json.Marshal(repository.MainBranch.Name)
+		c.cache.SetWithTTL(key, encoded, mainBranchTTL)
+	}
+	return repository.MainBranch.Name, nil
+}
+
 func (c *Client) ListRepositories(workspace string) ([]Repository, error) {
 	var repos []Repository
 	path := fmt.Sprintf("/repositories/%s", workspace)
 
+	pages := 0
 	for path != "" {
 		data, err := c.get(path)
+		if errors.Is(err, ErrForbidden) {
+			return nil, NewWorkspaceAccessError(workspace)
+		}
 		if err != nil {
 			return nil, err
 		}
@@ -233,26 +880,42 @@ func (c *Client) ListRepositories(workspace string) ([]Repository, error) {
 		}
 
 		repos = append(repos, page.Values...)
-		path = extractNextPath(page.Next)
+		path = c.nextPath(path, page.Next, &pages)
 	}
 
 	return repos, nil
 }
 
 func (c *Client) ListPullRequests(workspace, repo string, opts *PRListOptions) ([]PullRequest, error) {
-	var prs []PullRequest
 	path := fmt.Sprintf("/repositories/%s/%s/pullrequests", workspace, repo)
 
-	var queryParams []string
-	if opts != nil {
-		if opts.State != "" {
-			queryParams = append(queryParams, "state="+opts.State)
-		}
-	}
-	if len(queryParams) > 0 {
-		path += "?" + strings.Join(queryParams, "&")
+	if filter := pullRequestFilter(opts); filter != "" {
+		path += "?" + filter
 	}
 
+	return c.listPullRequests(path)
+}
+
+// ListMergedPullRequests returns the pull requests merged in [from, to).
+// Bitbucket records no merge time, so updated_on stands in for it; a PR
+// touched after merging (e.g. a late comment) can fall outside the range.
+func (c *Client) ListMergedPullRequests(workspace, repo string, from, to time.Time) ([]PullRequest, error) {
+	path := fmt.Sprintf("/repositories/%s/%s/pullrequests?q=%s", workspace, repo, url.QueryEscape(mergedQuery(from, to)))
+	return c.listPullRequests(path)
+}
+
+// mergedQuery renders state="MERGED" AND updated_on >= <from> AND updated_on < <to>.
+// BBQL takes datetimes as unquoted ISO 8601 values.
+func mergedQuery(from, to time.Time) string {
+	return fmt.Sprintf(`state="MERGED" AND updated_on >= %s AND updated_on < %s`,
+		from.UTC().Format(time.RFC3339), to.UTC().Format(time.RFC3339))
+}
+
+func (c *Client) listPullRequests(path string) ([]PullRequest, error) {
+	var prs []PullRequest
+	seen := make(map[int]bool)
+
+	pages := 0
 	for path != "" {
 		data, err := c.get(path)
 		if err != nil {
@@ -265,52 +928,185 @@ func (c *Client) ListPullRequests(workspace, repo string, opts *PRListOptions) (
 		}
 
 		for _, pr := range page.Values {
-			if opts != nil && opts.Author != "" && pr.Author.Username != opts.Author {
-				continue
-			}
-			if opts != nil && opts.Reviewer != "" && !hasReviewer(pr, opts.Reviewer) {
+			if seen[pr.ID] {
 				continue
 			}
+			seen[pr.ID] = true
 			prs = append(prs, pr)
 		}
-		path = extractNextPath(page.Next)
+		path = c.nextPath(path, page.Next, &pages)
 	}
 
 	return prs, nil
 }
 
-func hasReviewer(pr PullRequest, reviewer string) bool {
-	for _, r := range pr.Reviewers {
-		if r.Username == reviewer {
-			return true
+// pullRequestFilter builds the query string for listing pull requests. Author
+// and reviewer filters need a BBQL q expression, which then carries the state
+// clause too; a state-only filter uses the simpler repeated state params.
+func pullRequestFilter(opts *PRListOptions) string {
+	if opts == nil || (opts.Author == "" && opts.Reviewer == "") {
+		return stateParams(opts)
+	}
+	return "q=" + url.QueryEscape(pullRequestQuery(opts))
+}
+
+// pullRequestQuery renders opts as a BBQL expression such as
+// author.username="a" AND reviewers.username="b" AND (state="OPEN" OR state="MERGED").
+// Values in {braces} are matched as UUIDs.
+func pullRequestQuery(opts *PRListOptions) string {
+	var clauses []string
+	if opts.Author != "" {
+		clauses = append(clauses, userClause("author", opts.Author))
+	}
+	if opts.Reviewer != "" {
+		clauses = append(clauses, userClause("reviewers", opts.Reviewer))
+	}
+	if len(opts.States) > 0 {
+		states := make([]string, 0, len(opts.States))
+		for _, state := range opts.States {
+			states = append(states, fmt.Sprintf(`state=%s`, quoteBBQL(state)))
 		}
+		clauses = append(clauses, "("+strings.Join(states, " OR ")+")")
+	}
+	return strings.Join(clauses, " AND ")
+}
+
+func userClause(field, user string) string {
+	if strings.HasPrefix(user, "{") {
+		return fmt.Sprintf("%s.uuid=%s", field, quoteBBQL(user))
+	}
+	return fmt.Sprintf("%s.username=%s", field, quoteBBQL(user))
+}
+
+func quoteBBQL(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	return `"` + strings.ReplaceAll(value, `"`, `\"`) + `"`
+}
+
+// The API accepts repeated state params and returns the union.
+func stateParams(opts *PRListOptions) string {
+	if opts == nil {
+		return ""
+	}
+	params := make([]string, 0, len(opts.States))
+	for _, state := range opts.States {
+		params = append(params, "state="+url.QueryEscape(state))
+	}
+	return strings.Join(params, "&")
+}
+
+func (c *Client) CountPullRequests(workspace, repo string, opts *PRListOptions) (int, error) {
+	path := fmt.Sprintf("/repositories/%s/%s/pullrequests?pagelen=1", workspace, repo)
+	if filter := pullRequestFilter(opts); filter != "" {
+		path += "&" + filter
+	}
+
+	data, err := c.get(path)
+	if err != nil {
+		return 0, err
+	}
+
+	var page PaginatedResponse[PullRequest]
+	if err := json.Unmarshal(data, &page); err != nil {
+		return 0, fmt.Errorf("failed to parse pull requests response: %w", err)
 	}
-	for _, p := range pr.Participants {
-		if p.Role == "REVIEWER" && p.User.Username == reviewer {
-			return true
+
+	if page.Size == 0 && len(page.Values) > 0 {
+		prs, err := c.ListPullRequests(workspace, repo, opts)
+		if err != nil {
+			return 0, err
 		}
+		return len(prs), nil
 	}
-	return false
+
+	return page.Size, nil
 }
 
+// Repositories are fetched concurrently. Per-repo failures do not abort the
+// listing; they are returned as a *PartialError alongside the other results.
 func (c *Client) ListAllPullRequests(workspace string, opts *PRListOptions) ([]PullRequest, error) {
 	repos, err := c.ListRepositories(workspace)
 	if err != nil {
 		return nil, err
 	}
 
+	concurrency := c.concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	results := make([][]PullRequest, len(repos))
+	errs := make([]error, len(repos))
+
+	var mu sync.Mutex
+	done := 0
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, repo := range repos {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			results[i], errs[i] = c.ListPullRequests(workspace, name, opts)
+
+			mu.Lock()
+			done++
+			c.reportProgress(done, len(repos))
+			mu.Unlock()
+		}(i, repo.Name)
+	}
+	wg.Wait()
+
 	var allPRs []PullRequest
-	for _, repo := range repos {
-		prs, err := c.ListPullRequests(workspace, repo.Name, opts)
-		if err != nil {
+	partial := &PartialError{}
+	for i, repo := range repos {
+		if errs[i] != nil {
+			partial.Errors = append(partial.Errors, fmt.Errorf("%s: %w", repo.Name, errs[i]))
 			continue
 		}
-		allPRs = append(allPRs, prs...)
+		allPRs = append(allPRs, results[i]...)
+	}
+
+	if opts != nil && opts.Limit > 0 && len(allPRs) > opts.Limit {
+		allPRs = allPRs[:opts.Limit]
 	}
 
+	if len(partial.Errors) > 0 {
+		return allPRs, partial
+	}
 	return allPRs, nil
 }
 
+// ListPullRequestsForReviewer lists the workspace's PRs that reviewer is
+// assigned to. It shares ListAllPullRequests' fan-out, so --concurrency and
+// --limit apply and per-repo failures come back as a *PartialError.
+func (c *Client) ListPullRequestsForReviewer(workspace, reviewer string, opts *PRListOptions) ([]PullRequest, error) {
+	uuid := reviewer
+	if !strings.HasPrefix(reviewer, "{") {
+		user, err := c.GetUser(reviewer)
+		if err != nil {
+			return nil, err
+		}
+		uuid = user.UUID
+	}
+
+	filter := PRListOptions{Reviewer: uuid}
+	if opts != nil {
+		filter.States = opts.States
+		filter.Author = opts.Author
+		filter.Limit = opts.Limit
+	}
+	return c.ListAllPullRequests(workspace, &filter)
+}
+
+func (c *Client) reportProgress(done, total int) {
+	if c.progress != nil {
+		c.progress(done, total)
+	}
+}
+
 func (c *Client) FindPullRequestByBranch(workspace, repo, branch string) (*PullRequest, error) {
 	path := fmt.Sprintf("/repositories/%s/%s/pullrequests?q=source.branch.name=\"%s\"", workspace, repo, branch)
 	data, err := c.get(path)
@@ -327,18 +1123,40 @@ func (c *Client) FindPullRequestByBranch(workspace, repo, branch string) (*PullR
 		return nil, NewNotFoundError("pull request", fmt.Sprintf("branch %s", branch))
 	}
 
+	return selectPullRequest(page.Values), nil
+}
+
+func (c *Client) FindPullRequestByCommit(workspace, repo, hash string) (*PullRequest, error) {
+	path := fmt.Sprintf("/repositories/%s/%s/commit/%s/pullrequests", workspace, repo, hash)
+	data, err := c.get(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var page PaginatedResponse[PullRequest]
+	if err := json.Unmarshal(data, &page); err != nil {
+		return nil, fmt.Errorf("failed to parse pull requests response: %w", err)
+	}
+
+	if len(page.Values) == 0 {
+		return nil, NewNotFoundError("pull request", fmt.Sprintf("commit %s", hash))
+	}
+
+	return selectPullRequest(page.Values), nil
+}
+
+func selectPullRequest(prs []PullRequest) *PullRequest {
 	var selected *PullRequest
-	for i := range page.Values {
-		pr := &page.Values[i]
+	for i := range prs {
+		pr := &prs[i]
 		if pr.State == "OPEN" {
-			return pr, nil
+			return pr
 		}
 		if selected == nil || pr.UpdatedOn.After(selected.UpdatedOn) {
 			selected = pr
 		}
 	}
-
-	return selected, nil
+	return selected
 }
 
 func (c *Client) GetPullRequest(workspace, repo string, id int) (*PullRequest, error) {
@@ -356,10 +1174,131 @@ func (c *Client) GetPullRequest(workspace, repo string, id int) (*PullRequest, e
 	return &pr, nil
 }
 
+func (c *Client) GetPullRequestRaw(workspace, repo string, id int) ([]byte, error) {
+	path := fmt.Sprintf("/repositories/%s/%s/pullrequests/%d", workspace, repo, id)
+	return c.get(path)
+}
+
+func (c *Client) UpdatePullRequestReviewers(workspace, repo string, id int, add, remove []string) (*PullRequest, error) {
+	addUUIDs, err := c.resolveUserUUIDs(add)
+	if err != nil {
+		return nil, err
+	}
+	removeUUIDs, err := c.resolveUserUUIDs(remove)
+	if err != nil {
+		return nil, err
+	}
+
+	// Read the raw document so writable fields we don't model (e.g.
+	// close_source_branch) are sent back unchanged.
+	path := fmt.Sprintf("/repositories/%s/%s/pullrequests/%d", workspace, repo, id)
+	data, err := c.getRaw(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse pull request response: %w", err)
+	}
+
+	var current []User
+	if encoded, err := json.Marshal(raw["reviewers"]); err == nil {
+		json.Unmarshal(encoded, &current)
+	}
+
+	payload := writablePullRequestFields(raw)
+	payload["reviewers"] = mergeReviewers(current, addUUIDs, removeUUIDs)
+
+	body, err := c.sendJSON(http.MethodPut, path, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	var pr PullRequest
+	if err := json.Unmarshal(body, &pr); err != nil {
+		return nil, fmt.Errorf("failed to parse pull request response: %w", err)
+	}
+
+	return &pr, nil
+}
+
+// writablePullRequestFields copies the fields a PR update may set out of a
+// GET response. Read-only fields (links, participants, summary.html,
+// timestamps, ...) are left out so a PUT can't clobber them.
+func writablePullRequestFields(raw map[string]any) map[string]any {
+	payload := make(map[string]any)
+	for _, key := range []string{"title", "description", "close_source_branch"} {
+		if value, ok := raw[key]; ok {
+			payload[key] = value
+		}
+	}
+	if dest, ok := raw["destination"].(map[string]any); ok {
+		payload["destination"] = map[string]any{"branch": dest["branch"]}
+	}
+	return payload
+}
+
+// resolveUserUUIDs maps usernames, {uuid} values and the me/@me alias to
+// account UUIDs.
+func (c *Client) resolveUserUUIDs(usernames []string) ([]string, error) {
+	var uuids []string
+	for _, name := range usernames {
+		if strings.HasPrefix(name, "{") {
+			uuids = append(uuids, name)
+			continue
+		}
+		if IsSelfAlias(name) {
+			user, err := c.GetCurrentUser()
+			if err != nil {
+				return nil, fmt.Errorf("failed to resolve '%s' to the authenticated user: %w", name, err)
+			}
+			uuids = append(uuids, user.UUID)
+			continue
+		}
+		user, err := c.GetUser(name)
+		if err != nil {
+			return nil, err
+		}
+		uuids = append(uuids, user.UUID)
+	}
+	return uuids, nil
+}
+
+func mergeReviewers(current []User, add, remove []string) []map[string]string {
+	removed := make(map[string]bool)
+	for _, uuid := range remove {
+		removed[uuid] = true
+	}
+
+	seen := make(map[string]bool)
+	var merged []map[string]string
+	for _, r := range current {
+		if removed[r.UUID] || seen[r.UUID] {
+			continue
+		}
+		seen[r.UUID] = true
+		merged = append(merged, map[string]string{"uuid": r.UUID})
+	}
+	for _, uuid := range add {
+		if removed[uuid] || seen[uuid] {
+			continue
+		}
+		seen[uuid] = true
+		merged = append(merged, map[string]string{"uuid": uuid})
+	}
+
+	if merged == nil {
+		merged = []map[string]string{}
+	}
+	return merged
+}
+
 func (c *Client) ListPullRequestComments(workspace, repo string, id int) ([]Comment, error) {
 	var comments []Comment
 	path := fmt.Sprintf("/repositories/%s/%s/pullrequests/%d/comments", workspace, repo, id)
 
+	pages := 0
 	for path != "" {
 		data, err := c.get(path)
 		if err != nil {
@@ -372,21 +1311,110 @@ func (c *Client) ListPullRequestComments(workspace, repo string, id int) ([]Comm
 		}
 
 		comments = append(comments, page.Values...)
-		path = extractNextPath(page.Next)
+		path = c.nextPath(path, page.Next, &pages)
 	}
 
 	return comments, nil
 }
 
+// CreatePullRequestComment posts a comment on a pull request. A non-nil
+// inline anchors it to a file (and, with To set, a line on the new side).
+func (c *Client) CreatePullRequestComment(workspace, repo string, id int, body string, inline *Inline) (*Comment, error) {
+	path := fmt.Sprintf("/repositories/%s/%s/pullrequests/%d/comments", workspace, repo, id)
+	payload := map[string]any{
+		"content": map[string]string{"raw": body},
+	}
+	if inline != nil {
+		payload["inline"] = inline
+	}
+
+	data, err := c.sendJSON(http.MethodPost, path, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	var comment Comment
+	if err := json.Unmarshal(data, &comment); err != nil {
+		return nil, fmt.Errorf("failed to parse comment response: %w", err)
+	}
+
+	return &comment, nil
+}
+
 func (c *Client) GetPullRequestDiff(workspace, repo string, id int) ([]byte, error) {
 	path := fmt.Sprintf("/repositories/%s/%s/pullrequests/%d/diff", workspace, repo, id)
 	return c.getRaw(path)
 }
 
+func (c *Client) GetPullRequestDiffStream(workspace, repo string, id int) (io.ReadCloser, error) {
+	path := fmt.Sprintf("/repositories/%s/%s/pullrequests/%d/diff", workspace, repo, id)
+	return c.getStream(path)
+}
+
+func (c *Client) ListPullRequestStatuses(workspace, repo string, id int) ([]CommitStatus, error) {
+	var statuses []CommitStatus
+	path := fmt.Sprintf("/repositories/%s/%s/pullrequests/%d/statuses", workspace, repo, id)
+
+	pages := 0
+	for path != "" {
+		data, err := c.get(path)
+		if err != nil {
+			if errors.Is(err, ErrNotFound) {
+				return []CommitStatus{}, nil
+			}
+			return nil, err
+		}
+
+		var page PaginatedResponse[CommitStatus]
+		if err := json.Unmarshal(data, &page); err != nil {
+			return nil, fmt.Errorf("failed to parse statuses response: %w", err)
+		}
+
+		statuses = append(statuses, page.Values...)
+		path = c.nextPath(path, page.Next, &pages)
+	}
+
+	return statuses, nil
+}
+
+// ListPullRequestApprovals returns the time of each user's most recent
+// approval, keyed by username, from the PR activity feed.
+func (c *Client) ListPullRequestApprovals(workspace, repo string, id int) (map[string]time.Time, error) {
+	approvals := make(map[string]time.Time)
+	path := fmt.Sprintf("/repositories/%s/%s/pullrequests/%d/activity", workspace, repo, id)
+
+	pages := 0
+	for path != "" {
+		data, err := c.get(path)
+		if err != nil {
+			return nil, err
+		}
+
+		var page PaginatedResponse[Activity]
+		if err := json.Unmarshal(data, &page); err != nil {
+			return nil, fmt.Errorf("failed to parse activity response: %w", err)
+		}
+
+		for _, activity := range page.Values {
+			if activity.Approval == nil {
+				continue
+			}
+			username := activity.Approval.User.Username
+			if activity.Approval.Date.After(approvals[username]) {
+				approvals[username] = activity.Approval.Date
+			}
+		}
+		path = c.nextPath(path, page.Next, &pages)
+	}
+
+	return approvals, nil
+}
+
 func (c *Client) ListPullRequestTasks(workspace, repo string, id int) ([]Task, error) {
 	var tasks []Task
 	path := fmt.Sprintf("/repositories/%s/%s/pullrequests/%d/tasks", workspace, repo, id)
 
+	pages := 0
 	for path != "" {
 		data, err := c.get(path)
 		if err != nil {
@@ -402,18 +1430,66 @@ func (c *Client) ListPullRequestTasks(workspace, repo string, id int) ([]Task, e
 		}
 
 		tasks = append(tasks, page.Values...)
-		path = extractNextPath(page.Next)
+		path = c.nextPath(path, page.Next, &pages)
 	}
 
 	return tasks, nil
 }
 
-func extractNextPath(nextURL string) string {
+// The commits endpoint has no server-side message filter, so matching is done
+// client-side over a bounded number of pages.
+func (c *Client) SearchCommits(workspace, repo, query string, limit int) ([]CommitDetail, error) {
+	var commits []CommitDetail
+	path := fmt.Sprintf("/repositories/%s/%s/commits", workspace, repo)
+	query = strings.ToLower(query)
+
+	for pages := 0; path != "" && pages < maxCommitSearchPages; {
+		data, err := c.get(path)
+		if err != nil {
+			return nil, err
+		}
+
+		var page PaginatedResponse[CommitDetail]
+		if err := json.Unmarshal(data, &page); err != nil {
+			return nil, fmt.Errorf("failed to parse commits response: %w", err)
+		}
+
+		for _, commit := range page.Values {
+			if query != "" && !strings.Contains(strings.ToLower(commit.Message), query) {
+				continue
+			}
+			commits = append(commits, commit)
+			if limit > 0 && len(commits) >= limit {
+				return commits, nil
+			}
+		}
+		path = c.nextPath(path, page.Next, &pages)
+	}
+
+	return commits, nil
+}
+
+// nextPath advances a pagination loop, stopping once the client's page cap is
+// reached and reporting the truncation through the WithPageCapWarning callback.
+func (c *Client) nextPath(current, next string, pages *int) string {
+	*pages++
+	path := extractNextPath(c.baseURL, next)
+	if path != "" && c.maxPages > 0 && *pages >= c.maxPages {
+		if c.pageCapWarning != nil {
+			endpoint, _, _ := strings.Cut(current, "?")
+			c.pageCapWarning(endpoint, c.maxPages)
+		}
+		return ""
+	}
+	return path
+}
+
+func extractNextPath(base, nextURL string) string {
 	if nextURL == "" {
 		return ""
 	}
-	if len(nextURL) > len(baseURL) && nextURL[:len(baseURL)] == baseURL {
-		return nextURL[len(baseURL):]
+	if len(nextURL) > len(base) && nextURL[:len(base)] == base {
+		return nextURL[len(base):]
 	}
 	return ""
 }
@@ -422,6 +1498,33 @@ func (c *Client) ListSnippets(workspace string) ([]Snippet, error) {
 	var snippets []Snippet
 	path := fmt.Sprintf("/snippets/%s", workspace)
 
+	pages := 0
+	for path != "" {
+		data, err := c.get(path)
+		if errors.Is(err, ErrForbidden) {
+			return nil, NewWorkspaceAccessError(workspace)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var page PaginatedResponse[Snippet]
+		if err := json.Unmarshal(data, &page); err != nil {
+			return nil, fmt.Errorf("failed to parse snippets response: %w", err)
+		}
+
+		snippets = append(snippets, page.Values...)
+		path = c.nextPath(path, page.Next, &pages)
+	}
+
+	return snippets, nil
+}
+
+func (c *Client) ListAllSnippets() ([]Snippet, error) {
+	var snippets []Snippet
+	path := "/snippets?role=member"
+
+	pages := 0
 	for path != "" {
 		data, err := c.get(path)
 		if err != nil {
@@ -434,14 +1537,21 @@ func (c *Client) ListSnippets(workspace string) ([]Snippet, error) {
 		}
 
 		snippets = append(snippets, page.Values...)
-		path = extractNextPath(page.Next)
+		path = c.nextPath(path, page.Next, &pages)
 	}
 
 	return snippets, nil
 }
 
 func (c *Client) GetSnippet(workspace, id string) (*Snippet, error) {
+	return c.GetSnippetAt(workspace, id, "")
+}
+
+func (c *Client) GetSnippetAt(workspace, id, revision string) (*Snippet, error) {
 	path := fmt.Sprintf("/snippets/%s/%s", workspace, id)
+	if revision != "" {
+		path += "/" + revision
+	}
 	data, err := c.get(path)
 	if err != nil {
 		return nil, err
@@ -456,12 +1566,42 @@ func (c *Client) GetSnippet(workspace, id string) (*Snippet, error) {
 }
 
 func (c *Client) GetSnippetFileContent(workspace, id, filename string) ([]byte, error) {
+	return c.GetSnippetFileContentAt(workspace, id, "", filename)
+}
+
+func (c *Client) GetSnippetFileContentAt(workspace, id, revision, filename string) ([]byte, error) {
 	path := fmt.Sprintf("/snippets/%s/%s/files/%s", workspace, id, filename)
+	if revision != "" {
+		path = fmt.Sprintf("/snippets/%s/%s/%s/files/%s", workspace, id, revision, filename)
+	}
 	return c.getRaw(path)
 }
 
+func (c *Client) ListSnippetCommits(workspace, id string) ([]CommitDetail, error) {
+	var commits []CommitDetail
+	path := fmt.Sprintf("/snippets/%s/%s/commits", workspace, id)
+
+	pages := 0
+	for path != "" {
+		data, err := c.get(path)
+		if err != nil {
+			return nil, err
+		}
+
+		var page PaginatedResponse[CommitDetail]
+		if err := json.Unmarshal(data, &page); err != nil {
+			return nil, fmt.Errorf("failed to parse snippet commits response: %w", err)
+		}
+
+		commits = append(commits, page.Values...)
+		path = c.nextPath(path, page.Next, &pages)
+	}
+
+	return commits, nil
+}
+
 func (c *Client) CreateSnippet(workspace, title string, files map[string][]byte, isPrivate bool) (*Snippet, error) {
-	url := baseURL + fmt.Sprintf("/snippets/%s", workspace)
+	url := c.baseURL + fmt.Sprintf("/snippets/%s", workspace)
 
 	var buf bytes.Buffer
 	writer := multipart.NewWriter(&buf)
@@ -488,7 +1628,7 @@ func (c *Client) CreateSnippet(workspace, title string, files map[string][]byte,
 		return nil, fmt.Errorf("failed to close multipart writer: %w", err)
 	}
 
-	req, err := http.NewRequest(http.MethodPost, url, &buf)
+	req, err := http.NewRequestWithContext(c.ctx, http.MethodPost, url, &buf)
 	if err != nil {
 		return nil, err
 	}
@@ -517,12 +1657,18 @@ func (c *Client) CreateSnippet(workspace, title string, files map[string][]byte,
 	return &snippet, nil
 }
 
-func (c *Client) UpdateSnippet(workspace, id string, addFiles map[string][]byte, removeFiles []string) error {
-	url := baseURL + fmt.Sprintf("/snippets/%s/%s", workspace, id)
+func (c *Client) UpdateSnippet(workspace, id string, addFiles map[string][]byte, removeFiles []string, isPrivate *bool) error {
+	url := c.baseURL + fmt.Sprintf("/snippets/%s/%s", workspace, id)
 
 	var buf bytes.Buffer
 	writer := multipart.NewWriter(&buf)
 
+	if isPrivate != nil {
+		if err := writer.WriteField("is_private", strconv.FormatBool(*isPrivate)); err != nil {
+			return fmt.Errorf("failed to write is_private field: %w", err)
+		}
+	}
+
 	for filename, content := range addFiles {
 		part, err := writer.CreateFormFile("file", filename)
 		if err != nil {
@@ -543,7 +1689,7 @@ func (c *Client) UpdateSnippet(workspace, id string, addFiles map[string][]byte,
 		return fmt.Errorf("failed to close multipart writer: %w", err)
 	}
 
-	req, err := http.NewRequest(http.MethodPut, url, &buf)
+	req, err := http.NewRequestWithContext(c.ctx, http.MethodPut, url, &buf)
 	if err != nil {
 		return err
 	}
@@ -564,9 +1710,9 @@ func (c *Client) UpdateSnippet(workspace, id string, addFiles map[string][]byte,
 }
 
 func (c *Client) DeleteSnippet(workspace, id string) error {
-	url := baseURL + fmt.Sprintf("/snippets/%s/%s", workspace, id)
+	url := c.baseURL + fmt.Sprintf("/snippets/%s/%s", workspace, id)
 
-	req, err := http.NewRequest(http.MethodDelete, url, nil)
+	req, err := http.NewRequestWithContext(c.ctx, http.MethodDelete, url, nil)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,74 @@
+package bitbucket
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// newTestClient returns a client that talks to an httptest server running
+// handler and caches into a temporary directory. It bypasses NewClient so no
+// user config is read.
+func newTestClient(t *testing.T, handler http.Handler, opts ...ClientOption) (*Client, *httptest.Server) {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	httpClient := srv.Client()
+	httpClient.CheckRedirect = stayOnAPIHost
+
+	c := &Client{
+		ctx:         context.Background(),
+		httpClient:  httpClient,
+		baseURL:     srv.URL,
+		username:    "user",
+		password:    "secret",
+		cache:       &Cache{dir: t.TempDir(), ttl: defaultTTL},
+		concurrency: defaultConcurrency,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, srv
+}
+
+func TestDeadline(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+	}{
+		{"slow response", func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-time.After(5 * time.Second):
+			case <-r.Context().Done():
+			}
+		}},
+		{"rate limit wait", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Retry-After", "60")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			c, _ := newTestClient(t, tt.handler, WithContext(ctx), WithRetry(true), WithNoCache(true))
+
+			start := time.Now()
+			_, err := c.get("/user")
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Fatalf("err = %v, want context.DeadlineExceeded", err)
+			}
+			if elapsed := time.Since(start); elapsed > 2*time.Second {
+				t.Errorf("request took %s, want it cut short by the deadline", elapsed)
+			}
+			if code := ExitCodeFromError(err); code != ExitDeadline {
+				t.Errorf("exit code = %d, want %d", code, ExitDeadline)
+			}
+		})
+	}
+}
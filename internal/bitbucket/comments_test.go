@@ -0,0 +1,48 @@
+package bitbucket
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestCreatePullRequestComment(t *testing.T) {
+	line := 12
+
+	tests := []struct {
+		name     string
+		inline   *Inline
+		wantBody string
+	}{
+		{"general", nil, `{"content":{"raw":"looks good"}}`},
+		{"file", &Inline{Path: "main.go"}, `{"content":{"raw":"looks good"},"inline":{"path":"main.go"}}`},
+		{"file and line", &Inline{Path: "main.go", To: &line}, `{"content":{"raw":"looks good"},"inline":{"path":"main.go","to":12}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body string
+			c, _ := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPost || r.URL.Path != "/repositories/ws/repo/pullrequests/7/comments" {
+					t.Errorf("request = %s %s", r.Method, r.URL.Path)
+				}
+				data, _ := io.ReadAll(r.Body)
+				body = string(data)
+				w.WriteHeader(http.StatusCreated)
+				fmt.Fprint(w, `{"id": 99, "content": {"raw": "looks good"}}`)
+			}))
+
+			comment, err := c.CreatePullRequestComment("ws", "repo", 7, "looks good", tt.inline)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if comment.ID != 99 {
+				t.Errorf("comment id = %d, want 99", comment.ID)
+			}
+			if body != tt.wantBody {
+				t.Errorf("body = %s, want %s", body, tt.wantBody)
+			}
+		})
+	}
+}
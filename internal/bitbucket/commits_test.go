@@ -0,0 +1,51 @@
+package bitbucket
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestSearchCommits(t *testing.T) {
+	var srvURL string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "2" {
+			fmt.Fprint(w, `{"values": [{"hash": "c3", "message": "Fix login redirect"}]}`)
+			return
+		}
+		fmt.Fprintf(w, `{"values": [{"hash": "c1", "message": "FIX LOGIN timeout"}, {"hash": "c2", "message": "Bump deps"}],
+			"next": "%s/repositories/ws/repo/commits?page=2"}`, srvURL)
+	})
+
+	tests := []struct {
+		name  string
+		query string
+		limit int
+		want  []string
+	}{
+		{"case-insensitive across pages", "fix login", 0, []string{"c1", "c3"}},
+		{"limit stops early", "fix login", 1, []string{"c1"}},
+		{"empty query lists all", "", 0, []string{"c1", "c2", "c3"}},
+		{"no match", "nothing", 0, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, srv := newTestClient(t, handler, WithNoCache(true))
+			srvURL = srv.URL
+
+			commits, err := c.SearchCommits("ws", "repo", tt.query, tt.limit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, commit := range commits {
+				got = append(got, commit.Hash)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("commits = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
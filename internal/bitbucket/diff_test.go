@@ -0,0 +1,58 @@
+package bitbucket
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestGetPullRequestDiffStream(t *testing.T) {
+	release := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if accept := r.Header.Get("Accept"); accept != "*/*" {
+			t.Errorf("Accept = %q, want */*", accept)
+		}
+		io.WriteString(w, "diff --git a/one b/one\n")
+		w.(http.Flusher).Flush()
+		// Hold the rest back until the client has seen the first line.
+		select {
+		case <-release:
+		case <-time.After(5 * time.Second):
+		}
+		io.WriteString(w, "diff --git a/two b/two\n")
+	})
+	c, _ := newTestClient(t, handler, WithNoCache(true))
+
+	body, err := c.GetPullRequestDiffStream("ws", "repo", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer body.Close()
+
+	reader := bufio.NewReader(body)
+	first, err := reader.ReadString('\n')
+	if err != nil || first != "diff --git a/one b/one\n" {
+		t.Fatalf("first line = %q, %v; want it before the response completes", first, err)
+	}
+	close(release)
+
+	rest, err := io.ReadAll(reader)
+	if err != nil || string(rest) != "diff --git a/two b/two\n" {
+		t.Errorf("rest = %q, %v", rest, err)
+	}
+}
+
+func TestGetPullRequestDiffStreamError(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		io.WriteString(w, `{"type": "error", "error": {"message": "Not found"}}`)
+	})
+	c, _ := newTestClient(t, handler, WithNoCache(true))
+
+	if _, err := c.GetPullRequestDiffStream("ws", "repo", 1); !errors.Is(err, ErrNotFound) {
+		t.Errorf("err = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,73 @@
+package bitbucket
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestDryRun(t *testing.T) {
+	tests := []struct {
+		name      string
+		call      func(c *Client) error
+		wantSent  int32
+		wantErr   error
+		wantPrint string
+	}{
+		{
+			name:     "reads are sent",
+			call:     func(c *Client) error { _, err := c.get("/user"); return err },
+			wantSent: 1,
+		},
+		{
+			name: "json write is printed",
+			call: func(c *Client) error {
+				_, err := c.sendJSON(http.MethodPost, "/repositories/ws/repo/pullrequests/1/comments", map[string]any{"content": map[string]string{"raw": "hi"}})
+				return err
+			},
+			wantErr:   ErrDryRun,
+			wantPrint: "POST {srv}/repositories/ws/repo/pullrequests/1/comments\n{\n  \"content\": {\n    \"raw\": \"hi\"\n  }\n}\n",
+		},
+		{
+			name:      "bodiless write",
+			call:      func(c *Client) error { return c.DeleteSnippet("ws", "abc") },
+			wantErr:   ErrDryRun,
+			wantPrint: "DELETE {srv}/snippets/ws/abc\n",
+		},
+		{
+			name: "multipart write is summarized",
+			call: func(c *Client) error {
+				return c.UpdateSnippet("ws", "abc", map[string][]byte{"a.txt": []byte("x")}, nil, nil)
+			},
+			wantErr:   ErrDryRun,
+			wantPrint: "PUT {srv}/snippets/ws/abc\n<multipart/form-data; boundary=",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sent atomic.Int32
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				sent.Add(1)
+				w.Write([]byte(`{}`))
+			})
+			var out bytes.Buffer
+			c, srv := newTestClient(t, handler, WithDryRun(&out), WithNoCache(true))
+
+			err := tt.call(c)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if got := sent.Load(); got != tt.wantSent {
+				t.Errorf("requests sent = %d, want %d", got, tt.wantSent)
+			}
+			want := strings.ReplaceAll(tt.wantPrint, "{srv}", srv.URL)
+			if got := out.String(); !strings.HasPrefix(got, want) || (want == "" && got != "") {
+				t.Errorf("dry-run output = %q, want prefix %q", got, want)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package bitbucket
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"time"
@@ -12,6 +13,8 @@ var (
 	ErrNotFound     = errors.New("not found")
 	ErrRateLimited  = errors.New("rate limited")
 	ErrServerError  = errors.New("server error")
+	ErrDryRun       = errors.New("dry run: request not sent")
+	ErrOffline      = errors.New("not cached, offline")
 )
 
 const (
@@ -20,6 +23,8 @@ const (
 	ExitAuthError     = 4
 	ExitNotFoundError = 5
 	ExitRateLimited   = 6
+	ExitDeadline      = 7
+	ExitInterrupted   = 130
 )
 
 type APIError struct {
@@ -76,6 +81,25 @@ func NewAuthError(statusCode int, message string) *APIError {
 	}
 }
 
+func NewExpiredTokenError() *APIError {
+	err := NewAuthError(401, "app password expired or revoked")
+	err.Hint = "Create a new app password in Bitbucket and run 'atlas config set app_password'"
+	return err
+}
+
+func NewSSOError() *APIError {
+	err := NewAuthError(401, "account requires SSO or two-step verification")
+	err.Hint = "App passwords are rejected for this account; create an Atlassian API token (or use OAuth) and store it with 'atlas config set app_password'"
+	return err
+}
+
+func NewWorkspaceAccessError(workspace string) *APIError {
+	err := NewAuthError(403, fmt.Sprintf("no access to workspace '%s'", workspace))
+	err.Resource = "workspace"
+	err.Hint = "Check that your app password has the required scopes and that your account is a member of the workspace"
+	return err
+}
+
 func NewNotFoundError(resource, identifier string) *APIError {
 	return &APIError{
 		StatusCode: 404,
@@ -109,11 +133,29 @@ func NewServerError(statusCode int, message string) *APIError {
 	}
 }
 
+type PartialError struct {
+	Errors []error
+}
+
+func (e *PartialError) Error() string {
+	return fmt.Sprintf("%d request(s) failed", len(e.Errors))
+}
+
+func (e *PartialError) Unwrap() []error {
+	return e.Errors
+}
+
 func ExitCodeFromError(err error) int {
 	var apiErr *APIError
 	if errors.As(err, &apiErr) {
 		return apiErr.ExitCode()
 	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ExitDeadline
+	}
+	if errors.Is(err, context.Canceled) {
+		return ExitInterrupted
+	}
 	if err != nil {
 		return ExitGeneralError
 	}
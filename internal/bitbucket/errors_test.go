@@ -0,0 +1,44 @@
+package bitbucket
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestExitCodeFromError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"success", nil, ExitSuccess},
+		{"general", errors.New("boom"), ExitGeneralError},
+		{"not found", NewNotFoundError("repository", "ws/repo"), ExitNotFoundError},
+		{"deadline", fmt.Errorf("fetch: %w", context.DeadlineExceeded), ExitDeadline},
+		{"interrupted", fmt.Errorf("fetch: %w", context.Canceled), ExitInterrupted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExitCodeFromError(tt.err); got != tt.want {
+				t.Errorf("ExitCodeFromError(%v) = %d, want %d", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInterruptedRequestExitCode(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	c, _ := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cancel()
+		<-r.Context().Done()
+	}), WithContext(ctx), WithNoCache(true))
+
+	_, err := c.get("/user")
+	if code := ExitCodeFromError(err); code != ExitInterrupted {
+		t.Errorf("exit code = %d (err %v), want %d", code, err, ExitInterrupted)
+	}
+}
@@ -0,0 +1,412 @@
+package bitbucket
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestListPullRequestsForReviewer(t *testing.T) {
+	var mu sync.Mutex
+	var queries []string
+	record := func(r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		queries = append(queries, r.URL.Query().Get("q"))
+	}
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repositories/ws":
+			fmt.Fprint(w, `{"values":[{"name":"api"},{"name":"web"},{"name":"locked"}]}`)
+		case "/repositories/ws/api/pullrequests":
+			record(r)
+			fmt.Fprint(w, `{"values":[{"id":1},{"id":2}]}`)
+		case "/repositories/ws/web/pullrequests":
+			record(r)
+			fmt.Fprint(w, `{"values":[{"id":3}]}`)
+		case "/repositories/ws/locked/pullrequests":
+			w.WriteHeader(http.StatusForbidden)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	tests := []struct {
+		name    string
+		limit   int
+		wantPRs int
+	}{
+		{"all", 0, 3},
+		{"limited", 2, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queries = nil
+			c, _ := newTestClient(t, handler, WithConcurrency(1), WithNoCache(true))
+
+			prs, err := c.ListPullRequestsForReviewer("ws", "{abc}", &PRListOptions{States: []string{"OPEN"}, Limit: tt.limit})
+
+			var partial *PartialError
+			if !errors.As(err, &partial) || len(partial.Errors) != 1 {
+				t.Fatalf("err = %v, want a PartialError for the locked repo", err)
+			}
+			if !strings.Contains(partial.Errors[0].Error(), "locked") {
+				t.Errorf("partial error %q does not name the repository", partial.Errors[0])
+			}
+			if len(prs) != tt.wantPRs {
+				t.Errorf("got %d PRs, want %d", len(prs), tt.wantPRs)
+			}
+			for _, q := range queries {
+				if q != `reviewers.uuid="{abc}" AND (state="OPEN")` {
+					t.Errorf("q = %s", q)
+				}
+			}
+		})
+	}
+}
+
+func TestGetPullRequestRaw(t *testing.T) {
+	const doc = `{"id": 7, "title": "Add feature", "rendered": {"title": {"html": "<p>x</p>"}}, "x-unknown": [1, 2]}`
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repositories/ws/repo/pullrequests/7" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, doc)
+	})
+	c, _ := newTestClient(t, handler, WithNoCache(true))
+
+	data, err := c.GetPullRequestRaw("ws", "repo", 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != doc {
+		t.Errorf("raw body = %s, want the response passed through unchanged", data)
+	}
+}
+
+func TestCountPullRequests(t *testing.T) {
+	tests := []struct {
+		name      string
+		page      string
+		wantCount int
+		wantCalls int
+	}{
+		{"size reported", `{"size": 42, "pagelen": 1, "values": [{"id": 1}]}`, 42, 1},
+		{"empty", `{"size": 0, "values": []}`, 0, 1},
+		{"size missing", `{"values": [{"id": 1}, {"id": 2}, {"id": 3}]}`, 3, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int
+			var pagelens []string
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				pagelens = append(pagelens, r.URL.Query().Get("pagelen"))
+				fmt.Fprint(w, tt.page)
+			})
+			c, _ := newTestClient(t, handler, WithNoCache(true))
+
+			count, err := c.CountPullRequests("ws", "repo", &PRListOptions{States: []string{"OPEN"}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if count != tt.wantCount {
+				t.Errorf("count = %d, want %d", count, tt.wantCount)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("made %d requests, want %d", calls, tt.wantCalls)
+			}
+			if pagelens[0] != "1" {
+				t.Errorf("first request pagelen = %q, want 1 so only the size is fetched", pagelens[0])
+			}
+		})
+	}
+}
+
+func TestFindPullRequestByCommit(t *testing.T) {
+	tests := []struct {
+		name    string
+		page    string
+		wantID  int
+		wantErr error
+	}{
+		{"open wins", `{"values": [{"id": 1, "state": "MERGED", "updated_on": "2024-03-01T00:00:00Z"}, {"id": 2, "state": "OPEN", "updated_on": "2024-01-01T00:00:00Z"}]}`, 2, nil},
+		{"latest closed", `{"values": [{"id": 1, "state": "MERGED", "updated_on": "2024-01-01T00:00:00Z"}, {"id": 2, "state": "DECLINED", "updated_on": "2024-02-01T00:00:00Z"}]}`, 2, nil},
+		{"none", `{"values": []}`, 0, ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/repositories/ws/repo/commit/abc1234/pullrequests" {
+					http.NotFound(w, r)
+					return
+				}
+				fmt.Fprint(w, tt.page)
+			})
+			c, _ := newTestClient(t, handler, WithNoCache(true))
+
+			pr, err := c.FindPullRequestByCommit("ws", "repo", "abc1234")
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if pr.ID != tt.wantID {
+				t.Errorf("PR = %d, want %d", pr.ID, tt.wantID)
+			}
+		})
+	}
+}
+
+func TestListAllPullRequestsProgress(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/repositories/ws" {
+			fmt.Fprint(w, `{"values":[{"name":"a"},{"name":"b"},{"name":"c"}]}`)
+			return
+		}
+		fmt.Fprint(w, `{"values":[]}`)
+	})
+
+	var mu sync.Mutex
+	var done []int
+	c, _ := newTestClient(t, handler, WithNoCache(true), WithProgress(func(n, total int) {
+		mu.Lock()
+		defer mu.Unlock()
+		if total != 3 {
+			t.Errorf("total = %d, want 3", total)
+		}
+		done = append(done, n)
+	}))
+
+	if _, err := c.ListAllPullRequests("ws", &PRListOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(done) != 3 || done[len(done)-1] != 3 {
+		t.Errorf("progress updates = %v, want three ending at 3", done)
+	}
+}
+
+func TestListPullRequestsStates(t *testing.T) {
+	tests := []struct {
+		name   string
+		states []string
+		want   []string
+	}{
+		{"single", []string{"OPEN"}, []string{"OPEN"}},
+		{"several", []string{"OPEN", "MERGED"}, []string{"OPEN", "MERGED"}},
+		{"none", nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.URL.Query()["state"]
+				if q := r.URL.Query().Get("q"); q != "" {
+					t.Errorf("q = %s, want repeated state params without a query", q)
+				}
+				fmt.Fprint(w, `{"values": []}`)
+			})
+			c, _ := newTestClient(t, handler, WithNoCache(true))
+
+			if _, err := c.ListPullRequests("ws", "repo", &PRListOptions{States: tt.states}); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("state params = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListAllPullRequestsConcurrency(t *testing.T) {
+	const repos = 8
+
+	tests := []struct {
+		name        string
+		concurrency int
+	}{
+		{"serial", 1},
+		{"bounded", 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			inFlight, peak := 0, 0
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/repositories/ws" {
+					var values []string
+					for i := range repos {
+						values = append(values, fmt.Sprintf(`{"name": "r%d"}`, i))
+					}
+					fmt.Fprintf(w, `{"values": [%s]}`, strings.Join(values, ","))
+					return
+				}
+
+				mu.Lock()
+				inFlight++
+				peak = max(peak, inFlight)
+				mu.Unlock()
+				time.Sleep(10 * time.Millisecond)
+				mu.Lock()
+				inFlight--
+				mu.Unlock()
+
+				// PR IDs follow the repo index so ordering can be checked.
+				var id int
+				fmt.Sscanf(r.URL.Path, "/repositories/ws/r%d/pullrequests", &id)
+				fmt.Fprintf(w, `{"values": [{"id": %d}]}`, id)
+			})
+			c, _ := newTestClient(t, handler, WithNoCache(true), WithConcurrency(tt.concurrency))
+
+			prs, err := c.ListAllPullRequests("ws", &PRListOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if peak > tt.concurrency {
+				t.Errorf("peak in-flight requests = %d, want at most %d", peak, tt.concurrency)
+			}
+			if len(prs) != repos {
+				t.Fatalf("got %d PRs, want %d", len(prs), repos)
+			}
+			for i, pr := range prs {
+				if pr.ID != i {
+					t.Errorf("PR %d has ID %d; results must keep repository order", i, pr.ID)
+				}
+			}
+		})
+	}
+}
+
+func TestListPullRequestApprovals(t *testing.T) {
+	var srvURL string
+	pages := map[string]string{
+		"": `{"values": [
+			{"update": {"state": "OPEN"}},
+			{"approval": {"date": "2024-01-02T00:00:00Z", "user": {"username": "alice"}}},
+			{"comment": {"id": 1}}
+		], "next": "%s/repositories/ws/repo/pullrequests/7/activity?page=2"}`,
+		"2": `{"values": [
+			{"approval": {"date": "2024-01-05T00:00:00Z", "user": {"username": "alice"}}},
+			{"approval": {"date": "2024-01-03T00:00:00Z", "user": {"username": "bob"}}}
+		]}`,
+	}
+	c, srv := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repositories/ws/repo/pullrequests/7/activity" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		body := pages[r.URL.Query().Get("page")]
+		if strings.Contains(body, "%s") {
+			body = fmt.Sprintf(body, srvURL)
+		}
+		fmt.Fprint(w, body)
+	}), WithNoCache(true))
+	srvURL = srv.URL
+
+	approvals, err := c.ListPullRequestApprovals("ws", "repo", 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]time.Time{
+		"alice": time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC),
+		"bob":   time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC),
+	}
+	if len(approvals) != len(want) {
+		t.Fatalf("approvals = %v, want %v", approvals, want)
+	}
+	for user, at := range want {
+		if !approvals[user].Equal(at) {
+			t.Errorf("%s approved at %v, want the latest %v", user, approvals[user], at)
+		}
+	}
+}
+
+func TestPullRequestFilter(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      *PRListOptions
+		wantQuery string
+		wantRaw   string
+	}{
+		{name: "nil options"},
+		{name: "states only use params", opts: &PRListOptions{States: []string{"OPEN", "MERGED"}}, wantRaw: "state=OPEN&state=MERGED"},
+		{
+			name:      "author and states",
+			opts:      &PRListOptions{Author: "alice", States: []string{"OPEN", "MERGED"}},
+			wantQuery: `author.username="alice" AND (state="OPEN" OR state="MERGED")`,
+		},
+		{
+			name:      "uuid reviewer",
+			opts:      &PRListOptions{Reviewer: "{abc}"},
+			wantQuery: `reviewers.uuid="{abc}"`,
+		},
+		{
+			name:      "quotes and backslashes escaped",
+			opts:      &PRListOptions{Author: `a"b\c`, Reviewer: "bob"},
+			wantQuery: `author.username="a\"b\\c" AND reviewers.username="bob"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := pullRequestFilter(tt.opts)
+			if tt.wantQuery == "" {
+				if got != tt.wantRaw {
+					t.Errorf("filter = %q, want %q", got, tt.wantRaw)
+				}
+				return
+			}
+
+			values, err := url.ParseQuery(got)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if q := values.Get("q"); q != tt.wantQuery {
+				t.Errorf("q = %s, want %s", q, tt.wantQuery)
+			}
+		})
+	}
+}
+
+func TestListMergedPullRequests(t *testing.T) {
+	var q string
+	c, _ := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repositories/ws/repo/pullrequests" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		q = r.URL.Query().Get("q")
+		fmt.Fprint(w, `{"values": [{"id": 1, "state": "MERGED"}, {"id": 2, "state": "MERGED"}]}`)
+	}), WithNoCache(true))
+
+	// Non-UTC bounds are normalized so the query does not depend on the caller's zone.
+	est := time.FixedZone("EST", -5*60*60)
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, est)
+	to := time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)
+
+	prs, err := c.ListMergedPullRequests("ws", "repo", from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(prs) != 2 {
+		t.Errorf("got %d PRs, want 2", len(prs))
+	}
+	if want := `state="MERGED" AND updated_on >= 2024-03-01T05:00:00Z AND updated_on < 2024-03-15T00:00:00Z`; q != want {
+		t.Errorf("q = %s\nwant %s", q, want)
+	}
+}
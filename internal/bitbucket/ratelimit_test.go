@@ -0,0 +1,76 @@
+package bitbucket
+
+import (
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestRateLimitBudgetTracking(t *testing.T) {
+	tests := []struct {
+		name          string
+		limit         string
+		remaining     string
+		wantThrottled bool
+	}{
+		{"plenty left", "1000", "900", false},
+		{"at threshold", "1000", "100", false},
+		{"below threshold", "1000", "99", true},
+		{"no headers", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.limit != "" {
+					w.Header().Set("X-RateLimit-Limit", tt.limit)
+					w.Header().Set("X-RateLimit-Remaining", tt.remaining)
+				}
+				w.Write([]byte(`{}`))
+			})
+			c, _ := newTestClient(t, handler, WithNoCache(true))
+
+			if _, err := c.get("/user"); err != nil {
+				t.Fatal(err)
+			}
+			if got := c.Throttled(); got != tt.wantThrottled {
+				t.Errorf("Throttled() = %v, want %v", got, tt.wantThrottled)
+			}
+		})
+	}
+}
+
+// Streamed downloads (diffs, raw files) must go through the same request path
+// as JSON reads so they count against the budget and are observed.
+func TestStreamedReadsTrackRateLimit(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			t.Error("stream request sent without credentials")
+		}
+		w.Header().Set("X-RateLimit-Limit", "1000")
+		w.Header().Set("X-RateLimit-Remaining", "10")
+		w.Write([]byte("diff --git a/x b/x\n"))
+	})
+
+	var events []RequestEvent
+	c, _ := newTestClient(t, handler, WithObserver(func(e RequestEvent) {
+		events = append(events, e)
+	}))
+
+	body, err := c.getStream("/repositories/ws/repo/pullrequests/1/diff")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(body)
+	body.Close()
+
+	if string(data) != "diff --git a/x b/x\n" {
+		t.Errorf("body = %q", data)
+	}
+	if !c.Throttled() {
+		t.Error("stream response did not update the rate-limit budget")
+	}
+	if len(events) != 1 || !events[0].Final || events[0].Status != http.StatusOK {
+		t.Errorf("observer events = %+v, want one final 200", events)
+	}
+}
@@ -0,0 +1,113 @@
+package bitbucket
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestGetRepository(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		wantSlug string
+		wantErr  string
+	}{
+		{
+			name:     "details and clone links",
+			status:   http.StatusOK,
+			body:     `{"slug": "repo", "full_name": "ws/repo", "language": "go", "size": 2048, "mainbranch": {"name": "main"}, "links": {"clone": [{"name": "https", "href": "https://bitbucket.org/ws/repo.git"}]}}`,
+			wantSlug: "repo",
+		},
+		{
+			name:    "missing repository",
+			status:  http.StatusNotFound,
+			body:    `{"error": {"message": "not found"}}`,
+			wantErr: "repository 'ws/repo' not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/repositories/ws/repo" {
+					t.Errorf("path = %s", r.URL.Path)
+				}
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, tt.body)
+			}), WithNoCache(true))
+
+			repo, err := c.GetRepository("ws", "repo")
+			if tt.wantErr != "" {
+				var apiErr *APIError
+				if !errors.As(err, &apiErr) || apiErr.Message != tt.wantErr {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if repo.Slug != tt.wantSlug || repo.MainBranch == nil || repo.MainBranch.Name != "main" || repo.Size != 2048 {
+				t.Errorf("repo = %+v", repo)
+			}
+			if len(repo.Links.Clone) != 1 || repo.Links.Clone[0].Name != "https" {
+				t.Errorf("clone links = %+v", repo.Links.Clone)
+			}
+		})
+	}
+}
+
+func TestListRepositoriesPageCap(t *testing.T) {
+	const totalPages = 5
+
+	tests := []struct {
+		name      string
+		maxPages  int
+		wantRepos int
+		wantWarn  bool
+	}{
+		{"uncapped follows every page", 0, totalPages, false},
+		{"cap below total warns", 2, 2, true},
+		{"cap equal to total is silent", totalPages, totalPages, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var srvURL string
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				page := 1
+				fmt.Sscan(r.URL.Query().Get("page"), &page)
+				next := ""
+				if page < totalPages {
+					next = fmt.Sprintf(`, "next": "%s/repositories/ws?page=%d"`, srvURL, page+1)
+				}
+				fmt.Fprintf(w, `{"values": [{"slug": "repo-%d"}]%s}`, page, next)
+			})
+
+			var warnings []string
+			c, srv := newTestClient(t, handler, WithNoCache(true), WithMaxPages(tt.maxPages),
+				WithPageCapWarning(func(endpoint string, maxPages int) {
+					warnings = append(warnings, fmt.Sprintf("%s:%d", endpoint, maxPages))
+				}))
+			srvURL = srv.URL
+
+			repos, err := c.ListRepositories("ws")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(repos) != tt.wantRepos {
+				t.Errorf("repos = %d, want %d", len(repos), tt.wantRepos)
+			}
+			if tt.wantWarn {
+				if want := fmt.Sprintf("/repositories/ws:%d", tt.maxPages); len(warnings) != 1 || warnings[0] != want {
+					t.Errorf("warnings = %v, want [%s]", warnings, want)
+				}
+			} else if len(warnings) != 0 {
+				t.Errorf("unexpected warnings %v", warnings)
+			}
+		})
+	}
+}
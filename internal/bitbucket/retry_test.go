@@ -0,0 +1,186 @@
+package bitbucket
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// sequenceHandler answers each request with the next status in statuses and
+// repeats the last one once they run out. Bodies are recorded so retried
+// writes can be checked for a full replay.
+type sequenceHandler struct {
+	mu       sync.Mutex
+	statuses []int
+	bodies   []string
+}
+
+func (h *sequenceHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	h.mu.Lock()
+	status := h.statuses[min(len(h.bodies), len(h.statuses)-1)]
+	h.bodies = append(h.bodies, string(body))
+	h.mu.Unlock()
+
+	// Retry-After: 0 keeps 429 retries from sleeping.
+	w.Header().Set("Retry-After", "0")
+	w.WriteHeader(status)
+	w.Write([]byte(`{}`))
+}
+
+func (h *sequenceHandler) attempts() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.bodies)
+}
+
+func TestRetryOnlyIdempotentRequests(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       string
+		retry        bool
+		allowRetry   bool
+		statuses     []int
+		wantAttempts int
+	}{
+		{"GET retries after 500", http.MethodGet, true, false, []int{500, 200}, 2},
+		{"GET retries 429 up to the limit", http.MethodGet, true, false, []int{429}, maxRetries + 1},
+		{"GET without --retry", http.MethodGet, false, false, []int{500}, 1},
+		{"POST 500 tried once", http.MethodPost, true, false, []int{500}, 1},
+		{"POST 429 tried once", http.MethodPost, true, false, []int{429}, 1},
+		{"POST retried when allowed", http.MethodPost, true, true, []int{429, 429, 201}, 3},
+		{"PUT retries", http.MethodPut, true, false, []int{429, 200}, 2},
+		{"DELETE retries", http.MethodDelete, true, false, []int{429, 204}, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &sequenceHandler{statuses: tt.statuses}
+			ctx := context.Background()
+			if tt.allowRetry {
+				ctx = AllowRetry(ctx)
+			}
+			c, _ := newTestClient(t, h, WithRetry(tt.retry), WithContext(ctx), WithNoCache(true))
+
+			if tt.method == http.MethodGet {
+				c.get("/user")
+			} else {
+				c.sendJSON(tt.method, "/repositories/ws/repo/pullrequests", map[string]string{"title": "x"})
+			}
+
+			if got := h.attempts(); got != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", got, tt.wantAttempts)
+			}
+			for i, body := range h.bodies {
+				if tt.method != http.MethodGet && body != `{"title":"x"}` {
+					t.Errorf("attempt %d body = %q, want the full payload replayed", i+1, body)
+				}
+			}
+		})
+	}
+}
+
+func TestRetryStats(t *testing.T) {
+	tests := []struct {
+		name        string
+		statuses    []int
+		wantReports int
+		wantStats   RetryStats
+	}{
+		{"first try succeeds", []int{200}, 0, RetryStats{}},
+		{"rate limited twice", []int{429, 429, 200}, 1, RetryStats{Method: http.MethodGet, Attempts: 3, RateLimited: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var reports []RetryStats
+			h := &sequenceHandler{statuses: tt.statuses}
+			c, _ := newTestClient(t, h, WithRetry(true), WithNoCache(true), WithRetryStats(func(s RetryStats) {
+				reports = append(reports, s)
+			}))
+
+			if _, err := c.get("/user"); err != nil {
+				t.Fatal(err)
+			}
+			if len(reports) != tt.wantReports {
+				t.Fatalf("got %d reports, want %d", len(reports), tt.wantReports)
+			}
+			if tt.wantReports == 0 {
+				return
+			}
+			got := reports[0]
+			if got.Method != tt.wantStats.Method || got.Attempts != tt.wantStats.Attempts || got.RateLimited != tt.wantStats.RateLimited {
+				t.Errorf("stats = %+v, want %+v", got, tt.wantStats)
+			}
+			if got.URL == "" {
+				t.Error("stats URL is empty")
+			}
+		})
+	}
+}
+
+func TestParseRateLimitReset(t *testing.T) {
+	reset := time.Now().Add(90 * time.Second).Truncate(time.Second)
+
+	tests := []struct {
+		name   string
+		header http.Header
+		want   time.Duration
+	}{
+		{"retry-after wins", http.Header{"Retry-After": {"5"}, "X-Ratelimit-Reset": {strconv.FormatInt(reset.Unix(), 10)}}, 5 * time.Second},
+		{"reset timestamp", http.Header{"X-Ratelimit-Reset": {strconv.FormatInt(reset.Unix(), 10)}}, 90 * time.Second},
+		{"missing", http.Header{}, 60 * time.Second},
+		{"garbage", http.Header{"X-Ratelimit-Reset": {"soon"}}, 60 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := time.Until(parseRateLimitReset(tt.header))
+			if diff := got - tt.want; diff > 2*time.Second || diff < -2*time.Second {
+				t.Errorf("wait = %s, want about %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestObserverEvents(t *testing.T) {
+	tests := []struct {
+		name       string
+		statuses   []int
+		wantStatus []int
+	}{
+		{"single attempt", []int{200}, []int{200}},
+		{"retried attempts", []int{429, 429, 200}, []int{429, 429, 200}},
+		{"error status is final", []int{404}, []int{404}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var events []RequestEvent
+			h := &sequenceHandler{statuses: tt.statuses}
+			c, srv := newTestClient(t, h, WithRetry(true), WithNoCache(true), WithObserver(func(e RequestEvent) {
+				events = append(events, e)
+			}))
+
+			c.get("/user")
+
+			if len(events) != len(tt.wantStatus) {
+				t.Fatalf("events = %d, want %d", len(events), len(tt.wantStatus))
+			}
+			for i, e := range events {
+				last := i == len(events)-1
+				if e.Status != tt.wantStatus[i] || e.Attempt != i+1 || e.Final != last {
+					t.Errorf("event %d = %+v", i, e)
+				}
+				if e.Method != http.MethodGet || e.URL != srv.URL+"/user" || e.Err != nil {
+					t.Errorf("event %d = %+v", i, e)
+				}
+			}
+		})
+	}
+}
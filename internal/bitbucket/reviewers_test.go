@@ -0,0 +1,102 @@
+package bitbucket
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestUpdatePullRequestReviewers(t *testing.T) {
+	const prDoc = `{
+		"id": 7,
+		"title": "Add feature",
+		"description": "Body",
+		"close_source_branch": true,
+		"summary": {"raw": "Body", "html": "<p>Body</p>"},
+		"links": {"html": {"href": "https://bitbucket.org/ws/repo/pull-requests/7"}},
+		"participants": [{"user": {"uuid": "{old}"}, "approved": true}],
+		"created_on": "2024-01-01T00:00:00Z",
+		"destination": {"branch": {"name": "main"}, "commit": {"hash": "abc"}},
+		"reviewers": [{"uuid": "{old}"}, {"uuid": "{gone}"}]
+	}`
+
+	var putBody map[string]any
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/user":
+			fmt.Fprint(w, `{"uuid": "{self}", "username": "me"}`)
+		case r.URL.Path == "/users/alice":
+			fmt.Fprint(w, `{"uuid": "{alice}", "username": "alice"}`)
+		case r.Method == http.MethodGet:
+			fmt.Fprint(w, prDoc)
+		case r.Method == http.MethodPut:
+			data, _ := io.ReadAll(r.Body)
+			if err := json.Unmarshal(data, &putBody); err != nil {
+				t.Errorf("PUT body is not JSON: %s", data)
+			}
+			fmt.Fprint(w, prDoc)
+		}
+	})
+	c, _ := newTestClient(t, handler, WithNoCache(true))
+
+	if _, err := c.UpdatePullRequestReviewers("ws", "repo", 7, []string{"alice", "@me", "{old}"}, []string{"{gone}"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var keys []string
+	for key := range putBody {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	wantKeys := []string{"close_source_branch", "description", "destination", "reviewers", "title"}
+	if !reflect.DeepEqual(keys, wantKeys) {
+		t.Errorf("PUT fields = %v, want only the writable %v", keys, wantKeys)
+	}
+
+	wantDest := map[string]any{"branch": map[string]any{"name": "main"}}
+	if !reflect.DeepEqual(putBody["destination"], wantDest) {
+		t.Errorf("destination = %v, want %v", putBody["destination"], wantDest)
+	}
+
+	var reviewers []string
+	for _, r := range putBody["reviewers"].([]any) {
+		reviewers = append(reviewers, r.(map[string]any)["uuid"].(string))
+	}
+	wantReviewers := []string{"{old}", "{alice}", "{self}"}
+	if !reflect.DeepEqual(reviewers, wantReviewers) {
+		t.Errorf("reviewers = %v, want %v", reviewers, wantReviewers)
+	}
+}
+
+func TestMergeReviewers(t *testing.T) {
+	current := []User{{UUID: "{a}"}, {UUID: "{b}"}, {UUID: "{a}"}}
+
+	tests := []struct {
+		name   string
+		add    []string
+		remove []string
+		want   []string
+	}{
+		{"add new", []string{"{c}"}, nil, []string{"{a}", "{b}", "{c}"}},
+		{"add existing", []string{"{b}"}, nil, []string{"{a}", "{b}"}},
+		{"remove", nil, []string{"{a}"}, []string{"{b}"}},
+		{"remove wins over add", []string{"{c}"}, []string{"{c}"}, []string{"{a}", "{b}"}},
+		{"remove all", nil, []string{"{a}", "{b}"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, r := range mergeReviewers(current, tt.add, tt.remove) {
+				got = append(got, r["uuid"])
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("mergeReviewers = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package bitbucket
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestListAllSnippets(t *testing.T) {
+	var srvURL string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/snippets" || r.URL.Query().Get("role") != "member" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.URL.Query().Get("page") == "2" {
+			fmt.Fprint(w, `{"values": [{"id": "c", "workspace": {"slug": "beta"}}]}`)
+			return
+		}
+		fmt.Fprintf(w, `{"values": [{"id": "a", "workspace": {"slug": "alpha"}}, {"id": "b", "workspace": {"slug": "beta"}}],
+			"next": "%s/snippets?role=member&page=2"}`, srvURL)
+	})
+	c, srv := newTestClient(t, handler, WithNoCache(true))
+	srvURL = srv.URL
+
+	snippets, err := c.ListAllSnippets()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, s := range snippets {
+		got = append(got, s.Workspace.Slug+"/"+s.ID)
+	}
+	want := []string{"alpha/a", "beta/b", "beta/c"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("snippets = %v, want %v", got, want)
+	}
+}
+
+func TestUpdateSnippetVisibility(t *testing.T) {
+	private, public := true, false
+
+	tests := []struct {
+		name      string
+		isPrivate *bool
+		want      []string
+	}{
+		{"unchanged", nil, nil},
+		{"private", &private, []string{"true"}},
+		{"public", &public, []string{"false"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPut {
+					t.Errorf("method = %s, want PUT", r.Method)
+				}
+				if err := r.ParseMultipartForm(1 << 20); err != nil {
+					t.Fatal(err)
+				}
+				got = r.MultipartForm.Value["is_private"]
+				fmt.Fprint(w, `{}`)
+			})
+			c, _ := newTestClient(t, handler, WithNoCache(true))
+
+			if err := c.UpdateSnippet("ws", "abc", map[string][]byte{"a.txt": []byte("x")}, nil, tt.isPrivate); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("is_private = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSnippetRevisions(t *testing.T) {
+	var paths []string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		switch r.URL.Path {
+		case "/snippets/ws/abc/commits":
+			fmt.Fprint(w, `{"values": [{"hash": "r2", "message": "edit"}, {"hash": "r1", "message": "create"}]}`)
+		case "/snippets/ws/abc/files/a.txt", "/snippets/ws/abc/r1/files/a.txt":
+			fmt.Fprint(w, "content")
+		default:
+			fmt.Fprint(w, `{"id": "abc"}`)
+		}
+	})
+
+	tests := []struct {
+		name     string
+		call     func(c *Client) error
+		wantPath string
+	}{
+		{"history", func(c *Client) error { _, err := c.ListSnippetCommits("ws", "abc"); return err }, "/snippets/ws/abc/commits"},
+		{"latest", func(c *Client) error { _, err := c.GetSnippetAt("ws", "abc", ""); return err }, "/snippets/ws/abc"},
+		{"at revision", func(c *Client) error { _, err := c.GetSnippetAt("ws", "abc", "r1"); return err }, "/snippets/ws/abc/r1"},
+		{"latest file", func(c *Client) error { _, err := c.GetSnippetFileContentAt("ws", "abc", "", "a.txt"); return err }, "/snippets/ws/abc/files/a.txt"},
+		{"file at revision", func(c *Client) error { _, err := c.GetSnippetFileContentAt("ws", "abc", "r1", "a.txt"); return err }, "/snippets/ws/abc/r1/files/a.txt"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			paths = nil
+			c, _ := newTestClient(t, handler, WithNoCache(true))
+
+			if err := tt.call(c); err != nil {
+				t.Fatal(err)
+			}
+			if len(paths) != 1 || paths[0] != tt.wantPath {
+				t.Errorf("requested %v, want %s", paths, tt.wantPath)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package bitbucket
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestTransportPoolSettings(t *testing.T) {
+	tests := []struct {
+		name         string
+		opts         []ClientOption
+		wantIdle     int
+		wantMaxConns int
+	}{
+		{"defaults", nil, defaultMaxIdleConnsPerHost, defaultMaxConnsPerHost},
+		{"override", []ClientOption{WithConnectionPool(2, 8)}, 2, 8},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Client{httpClient: &http.Client{Transport: newTransport()}}
+			for _, opt := range tt.opts {
+				opt(c)
+			}
+
+			transport := c.httpClient.Transport.(*http.Transport)
+			if transport.MaxIdleConnsPerHost != tt.wantIdle || transport.MaxConnsPerHost != tt.wantMaxConns {
+				t.Errorf("pool = %d idle / %d max, want %d / %d",
+					transport.MaxIdleConnsPerHost, transport.MaxConnsPerHost, tt.wantIdle, tt.wantMaxConns)
+			}
+			if transport.IdleConnTimeout != defaultIdleConnTimeout {
+				t.Errorf("IdleConnTimeout = %s, want %s", transport.IdleConnTimeout, defaultIdleConnTimeout)
+			}
+		})
+	}
+}
+
+func TestConcurrentRequestsReuseConnections(t *testing.T) {
+	var conns atomic.Int32
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+	srv.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			conns.Add(1)
+		}
+	}
+	srv.Start()
+	t.Cleanup(srv.Close)
+
+	c := &Client{
+		ctx:        context.Background(),
+		httpClient: &http.Client{Transport: newTransport()},
+		baseURL:    srv.URL,
+		noCache:    true,
+	}
+
+	const workers, rounds = 4, 5
+	for range rounds {
+		var wg sync.WaitGroup
+		for range workers {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := c.get("/user"); err != nil {
+					t.Error(err)
+				}
+			}()
+		}
+		wg.Wait()
+	}
+
+	if n := conns.Load(); n > workers {
+		t.Errorf("opened %d connections for %d concurrent workers, want idle connections reused", n, workers)
+	}
+}
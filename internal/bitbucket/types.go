@@ -1,6 +1,10 @@
 package bitbucket
 
-import "time"
+import (
+	"fmt"
+	"strings"
+	"time"
+)
 
 type User struct {
 	UUID        string `json:"uuid"`
@@ -21,15 +25,29 @@ type Link struct {
 }
 
 type Repository struct {
-	UUID        string    `json:"uuid"`
-	Name        string    `json:"name"`
-	FullName    string    `json:"full_name"`
-	Description string    `json:"description"`
-	IsPrivate   bool      `json:"is_private"`
-	Owner       User      `json:"owner"`
-	CreatedOn   time.Time `json:"created_on"`
-	UpdatedOn   time.Time `json:"updated_on"`
-	Links       Links     `json:"links"`
+	UUID        string          `json:"uuid"`
+	Name        string          `json:"name"`
+	Slug        string          `json:"slug,omitempty"`
+	FullName    string          `json:"full_name"`
+	Description string          `json:"description"`
+	IsPrivate   bool            `json:"is_private"`
+	Language    string          `json:"language,omitempty"`
+	Size        int64           `json:"size,omitempty"`
+	MainBranch  *Branch         `json:"mainbranch,omitempty"`
+	Owner       User            `json:"owner"`
+	CreatedOn   time.Time       `json:"created_on"`
+	UpdatedOn   time.Time       `json:"updated_on"`
+	Links       RepositoryLinks `json:"links"`
+}
+
+type RepositoryLinks struct {
+	Links
+	Clone []CloneLink `json:"clone,omitempty"`
+}
+
+type CloneLink struct {
+	Name string `json:"name"`
+	Href string `json:"href"`
 }
 
 type PullRequest struct {
@@ -50,9 +68,10 @@ type PullRequest struct {
 }
 
 type PRListOptions struct {
-	State    string
+	States   []string
 	Author   string
 	Reviewer string
+	Limit    int
 }
 
 type PullRequestRef struct {
@@ -69,6 +88,41 @@ type Commit struct {
 	Hash string `json:"hash"`
 }
 
+type CommitDetail struct {
+	Hash    string       `json:"hash"`
+	Message string       `json:"message"`
+	Date    time.Time    `json:"date"`
+	Author  CommitAuthor `json:"author"`
+	Links   Links        `json:"links"`
+}
+
+type CommitAuthor struct {
+	Raw  string `json:"raw"`
+	User *User  `json:"user,omitempty"`
+}
+
+func (c *CommitDetail) ShortHash() string {
+	if len(c.Hash) > 12 {
+		return c.Hash[:12]
+	}
+	return c.Hash
+}
+
+func (c *CommitDetail) Summary() string {
+	summary, _, _ := strings.Cut(strings.TrimSpace(c.Message), "\n")
+	return strings.TrimSpace(summary)
+}
+
+func (a CommitAuthor) Name() string {
+	if a.User != nil && a.User.DisplayName != "" {
+		return a.User.DisplayName
+	}
+	if name, _, ok := strings.Cut(a.Raw, " <"); ok {
+		return name
+	}
+	return a.Raw
+}
+
 type Participant struct {
 	User     User   `json:"user"`
 	Role     string `json:"role"`
@@ -76,6 +130,17 @@ type Participant struct {
 	State    string `json:"state"`
 }
 
+// Activity is one entry of a PR's activity feed. Only approvals are decoded;
+// other event kinds leave Approval nil.
+type Activity struct {
+	Approval *Approval `json:"approval,omitempty"`
+}
+
+type Approval struct {
+	Date time.Time `json:"date"`
+	User User      `json:"user"`
+}
+
 type PullRequestLinks struct {
 	Self     Link `json:"self"`
 	HTML     Link `json:"html"`
@@ -144,11 +209,76 @@ func (t *Task) IsResolved() bool {
 	return t.State == "RESOLVED"
 }
 
+type CommitStatus struct {
+	Key   string `json:"key"`
+	Name  string `json:"name"`
+	State string `json:"state"`
+	URL   string `json:"url"`
+}
+
+type MergeReadiness struct {
+	Ready    bool
+	Blockers []string
+}
+
+func (pr *PullRequest) MergeReadiness(tasks []Task, statuses []CommitStatus) MergeReadiness {
+	var blockers []string
+
+	openTasks := 0
+	for _, t := range tasks {
+		if !t.IsResolved() {
+			openTasks++
+		}
+	}
+	if openTasks == 1 {
+		blockers = append(blockers, "1 task open")
+	} else if openTasks > 1 {
+		blockers = append(blockers, fmt.Sprintf("%d tasks open", openTasks))
+	}
+
+	approved := false
+	for _, p := range pr.Participants {
+		if p.State == "changes_requested" {
+			blockers = append(blockers, fmt.Sprintf("changes requested by @%s", p.User.Username))
+		}
+		if p.Approved {
+			approved = true
+		}
+	}
+	if !approved {
+		blockers = append(blockers, "no approvals")
+	}
+
+	failing, running := false, false
+	for _, s := range statuses {
+		switch s.State {
+		case "FAILED", "STOPPED":
+			failing = true
+		case "INPROGRESS":
+			running = true
+		}
+	}
+	if failing {
+		blockers = append(blockers, "CI failing")
+	} else if running {
+		blockers = append(blockers, "CI running")
+	}
+
+	return MergeReadiness{Ready: len(blockers) == 0, Blockers: blockers}
+}
+
+type Workspace struct {
+	UUID string `json:"uuid"`
+	Slug string `json:"slug"`
+	Name string `json:"name"`
+}
+
 type Snippet struct {
 	ID        string                 `json:"id"`
 	Title     string                 `json:"title"`
 	IsPrivate bool                   `json:"is_private"`
 	Owner     User                   `json:"owner"`
+	Workspace Workspace              `json:"workspace"`
 	Files     map[string]SnippetFile `json:"files"`
 	CreatedOn time.Time              `json:"created_on"`
 	UpdatedOn time.Time              `json:"updated_on"`
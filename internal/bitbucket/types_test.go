@@ -0,0 +1,65 @@
+package bitbucket
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMergeReadiness(t *testing.T) {
+	approved := []Participant{{User: User{Username: "amy"}, Approved: true}}
+
+	tests := []struct {
+		name         string
+		participants []Participant
+		tasks        []Task
+		statuses     []CommitStatus
+		wantBlockers []string
+	}{
+		{
+			name:         "ready",
+			participants: approved,
+			tasks:        []Task{{State: "RESOLVED"}},
+			statuses:     []CommitStatus{{State: "SUCCESSFUL"}},
+		},
+		{
+			name:         "no approvals",
+			wantBlockers: []string{"no approvals"},
+		},
+		{
+			name:         "open tasks",
+			participants: approved,
+			tasks:        []Task{{State: "UNRESOLVED"}, {State: "UNRESOLVED"}, {State: "RESOLVED"}},
+			wantBlockers: []string{"2 tasks open"},
+		},
+		{
+			name:         "changes requested",
+			participants: append([]Participant{{User: User{Username: "bob"}, State: "changes_requested"}}, approved...),
+			wantBlockers: []string{"changes requested by @bob"},
+		},
+		{
+			name:         "failing build wins over running",
+			participants: approved,
+			statuses:     []CommitStatus{{State: "INPROGRESS"}, {State: "FAILED"}},
+			wantBlockers: []string{"CI failing"},
+		},
+		{
+			name:         "running build",
+			participants: approved,
+			statuses:     []CommitStatus{{State: "INPROGRESS"}},
+			wantBlockers: []string{"CI running"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pr := &PullRequest{Participants: tt.participants}
+			got := pr.MergeReadiness(tt.tasks, tt.statuses)
+			if !reflect.DeepEqual(got.Blockers, tt.wantBlockers) {
+				t.Errorf("blockers = %q, want %q", got.Blockers, tt.wantBlockers)
+			}
+			if got.Ready != (len(tt.wantBlockers) == 0) {
+				t.Errorf("ready = %v with blockers %q", got.Ready, got.Blockers)
+			}
+		})
+	}
+}
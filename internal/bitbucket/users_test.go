@@ -0,0 +1,119 @@
+package bitbucket
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"sync/atomic"
+	"testing"
+)
+
+func TestIsSelfAlias(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"me", true},
+		{"@me", true},
+		{"Me", false},
+		{"meg", false},
+		{"@alice", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsSelfAlias(tt.name); got != tt.want {
+			t.Errorf("IsSelfAlias(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestResolveUserUUIDs(t *testing.T) {
+	var userCalls atomic.Int32
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/user":
+			userCalls.Add(1)
+			fmt.Fprint(w, `{"uuid": "{self}", "username": "me-user"}`)
+		case "/users/alice":
+			fmt.Fprint(w, `{"uuid": "{alice}", "username": "alice"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	tests := []struct {
+		name    string
+		in      []string
+		want    []string
+		wantErr bool
+	}{
+		{"uuid passthrough", []string{"{x}"}, []string{"{x}"}, false},
+		{"username lookup", []string{"alice"}, []string{"{alice}"}, false},
+		{"self aliases", []string{"me", "@me"}, []string{"{self}", "{self}"}, false},
+		{"unknown user", []string{"bob"}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := newTestClient(t, handler)
+
+			got, err := c.resolveUserUUIDs(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("resolveUserUUIDs(%v) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+
+	// me and @me in one call hit the disk-cached identity after the first lookup.
+	if n := userCalls.Load(); n != 1 {
+		t.Errorf("/user requested %d times, want 1", n)
+	}
+}
+
+func TestGetCurrentUserCachedPerCredentials(t *testing.T) {
+	tests := []struct {
+		name      string
+		password  string
+		noCache   bool
+		wantCalls int32
+	}{
+		{"same credentials reuse the cached identity", "secret", false, 1},
+		{"new app password refetches", "rotated", false, 2},
+		{"no cache refetches", "secret", true, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+				user, _, _ := r.BasicAuth()
+				fmt.Fprintf(w, `{"uuid": "{%s}", "username": "%s"}`, user, user)
+			})
+
+			first, _ := newTestClient(t, handler)
+			if _, err := first.GetCurrentUser(); err != nil {
+				t.Fatal(err)
+			}
+
+			// A later run sharing the same cache directory.
+			second, _ := newTestClient(t, handler, WithNoCache(tt.noCache))
+			second.cache = first.cache
+			second.password = tt.password
+			user, err := second.GetCurrentUser()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if user.UUID != "{user}" {
+				t.Errorf("uuid = %s", user.UUID)
+			}
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Errorf("/user requests = %d, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
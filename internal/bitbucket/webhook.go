@@ -0,0 +1,48 @@
+package bitbucket
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+type WebhookPullRequest struct {
+	Workspace string
+	Repo      string
+	ID        int
+}
+
+type webhookPayload struct {
+	PullRequest struct {
+		ID int `json:"id"`
+	} `json:"pullrequest"`
+	Repository struct {
+		Name      string    `json:"name"`
+		FullName  string    `json:"full_name"`
+		Workspace Workspace `json:"workspace"`
+	} `json:"repository"`
+}
+
+// ParsePullRequestWebhook extracts the PR coordinates from a Bitbucket
+// pullrequest:* webhook payload.
+func ParsePullRequestWebhook(body []byte) (*WebhookPullRequest, error) {
+	var payload webhookPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("failed to parse webhook payload: %w", err)
+	}
+
+	ref := &WebhookPullRequest{
+		Workspace: payload.Repository.Workspace.Slug,
+		Repo:      payload.Repository.Name,
+		ID:        payload.PullRequest.ID,
+	}
+	// full_name carries the slugs, which can differ from the display name
+	if ws, repo, ok := strings.Cut(payload.Repository.FullName, "/"); ok {
+		ref.Workspace, ref.Repo = ws, repo
+	}
+
+	if ref.Workspace == "" || ref.Repo == "" || ref.ID == 0 {
+		return nil, fmt.Errorf("webhook payload is missing pullrequest.id or repository details")
+	}
+	return ref, nil
+}
@@ -0,0 +1,52 @@
+package bitbucket
+
+import (
+	"testing"
+)
+
+func TestParsePullRequestWebhook(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    WebhookPullRequest
+		wantErr bool
+	}{
+		{
+			name: "full name slugs",
+			body: `{"pullrequest": {"id": 12}, "repository": {"name": "My Repo", "full_name": "acme/my-repo", "workspace": {"slug": "acme"}}}`,
+			want: WebhookPullRequest{Workspace: "acme", Repo: "my-repo", ID: 12},
+		},
+		{
+			name: "no full name",
+			body: `{"pullrequest": {"id": 3}, "repository": {"name": "api", "workspace": {"slug": "acme"}}}`,
+			want: WebhookPullRequest{Workspace: "acme", Repo: "api", ID: 3},
+		},
+		{
+			name:    "missing pull request",
+			body:    `{"repository": {"full_name": "acme/api"}}`,
+			wantErr: true,
+		},
+		{
+			name:    "missing repository",
+			body:    `{"pullrequest": {"id": 3}}`,
+			wantErr: true,
+		},
+		{
+			name:    "not json",
+			body:    `pullrequest=3`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePullRequestWebhook([]byte(tt.body))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && *got != tt.want {
+				t.Errorf("ParsePullRequestWebhook = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
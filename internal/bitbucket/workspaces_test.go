@@ -0,0 +1,79 @@
+package bitbucket
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestWorkspaceListingsExplainForbidden(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		list   func(c *Client) error
+		want   string
+	}{
+		{"repositories forbidden", http.StatusForbidden, func(c *Client) error { _, err := c.ListRepositories("secret"); return err }, "no access to workspace 'secret'"},
+		{"snippets forbidden", http.StatusForbidden, func(c *Client) error { _, err := c.ListSnippets("secret"); return err }, "no access to workspace 'secret'"},
+		{"repositories empty", http.StatusOK, func(c *Client) error { _, err := c.ListRepositories("secret"); return err }, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, `{"values": []}`)
+			})
+			c, _ := newTestClient(t, handler, WithNoCache(true))
+
+			err := tt.list(c)
+			if tt.want == "" {
+				if err != nil {
+					t.Fatalf("err = %v, want an empty listing", err)
+				}
+				return
+			}
+
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) || !strings.Contains(apiErr.Message, tt.want) {
+				t.Fatalf("err = %v, want a message containing %q", err, tt.want)
+			}
+			if apiErr.Hint == "" {
+				t.Error("workspace access error has no hint")
+			}
+			if !errors.Is(err, ErrForbidden) || ExitCodeFromError(err) != ExitAuthError {
+				t.Errorf("err should unwrap to ErrForbidden and exit with %d", ExitAuthError)
+			}
+		})
+	}
+}
+
+func TestListWorkspacesCached(t *testing.T) {
+	var calls int
+	var srvURL string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.URL.Query().Get("page") == "2" {
+			fmt.Fprint(w, `{"values": [{"slug": "beta", "name": "Beta"}]}`)
+			return
+		}
+		fmt.Fprintf(w, `{"values": [{"slug": "alpha", "name": "Alpha"}], "next": "%s/workspaces?page=2"}`, srvURL)
+	})
+	c, srv := newTestClient(t, handler)
+	srvURL = srv.URL
+
+	for i := range 2 {
+		workspaces, err := c.ListWorkspaces()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(workspaces) != 2 || workspaces[0].Slug != "alpha" || workspaces[1].Slug != "beta" {
+			t.Errorf("call %d: workspaces = %+v, want alpha and beta", i+1, workspaces)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("made %d requests, want 2 (one per page, then served from cache)", calls)
+	}
+}
@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/kabilan108/atlas/internal/bitbucket"
+	"github.com/kabilan108/atlas/internal/config"
+	"github.com/kabilan108/atlas/internal/output"
+	"github.com/spf13/cobra"
+)
+
+func newCommitCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "commit",
+		Short: "Work with commits",
+	}
+
+	cmd.AddCommand(newCommitListCmd())
+
+	return cmd
+}
+
+func newCommitListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List commits, optionally filtered by message",
+		Long: `List recent commits in a repository, newest first.
+
+Use --grep to keep only commits whose message contains the given text
+(case-insensitive). Bitbucket has no server-side message search, so matching
+happens locally over the most recent pages of history.`,
+		Args: cobra.NoArgs,
+		RunE: runCommitList,
+	}
+
+	cmd.Flags().String("repo", "", "Target repository (repo or workspace/repo)")
+	cmd.Flags().String("grep", "", "Only show commits whose message contains this text")
+	cmd.Flags().Int("limit", 30, "Maximum number of commits to show (0 for no limit)")
+	cmd.Flags().Bool("json", false, "Output as JSON")
+
+	return cmd
+}
+
+func runCommitList(cmd *cobra.Command, args []string) error {
+	repoFlag, _ := cmd.Flags().GetString("repo")
+	grep, _ := cmd.Flags().GetString("grep")
+	limit, _ := cmd.Flags().GetInt("limit")
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	jsonOutput := useJSONOutput(cmd, cfg)
+
+	workspace, repo, err := resolveRepository(repoFlag, cfg)
+	if err != nil {
+		return err
+	}
+
+	client, err := newClient(cmd, bitbucket.WithWorkspace(workspace))
+	if err != nil {
+		return err
+	}
+
+	commits, err := client.SearchCommits(workspace, repo, grep, limit)
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput {
+		return output.WriteJSON(os.Stdout, commits)
+	}
+
+	if len(commits) == 0 {
+		fmt.Println("No commits found.")
+		return nil
+	}
+
+	tw := output.NewTableWriter(os.Stdout, "Hash", "Author", "Date", "Message")
+	for _, c := range commits {
+		tw.AddRow(
+			c.ShortHash(),
+			c.Author.Name(),
+			output.FormatRelativeTime(c.Date),
+			output.Truncate(c.Summary(), 60),
+		)
+	}
+
+	return tw.Flush()
+}
@@ -4,10 +4,12 @@ import (
 	"bufio"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 
 	"github.com/kabilan108/atlas/internal/bitbucket"
 	"github.com/kabilan108/atlas/internal/config"
+	"github.com/kabilan108/atlas/internal/output"
 	"github.com/spf13/cobra"
 	"golang.org/x/term"
 )
@@ -20,16 +22,19 @@ func newConfigCmd() *cobra.Command {
 
 	cmd.AddCommand(newConfigSetCmd())
 	cmd.AddCommand(newConfigGetCmd())
+	cmd.AddCommand(newConfigListCmd())
 	cmd.AddCommand(newConfigVerifyCmd())
+	cmd.AddCommand(newConfigMigrateCmd())
 
 	return cmd
 }
 
 func newConfigSetCmd() *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "set <key> [value]",
 		Short: "Set a configuration value",
-		Long: `Set a configuration value. Valid keys: workspace, username, app_password.
+		Long: `Set a configuration value. Valid keys: workspace, username, app_password, default_repo,
+default_format, pr_default_state, pr_default_author, jira_site.
 
 For app_password, if no value is provided, you will be prompted to enter it interactively
 (hidden input). You can also pipe the value via stdin.
@@ -38,14 +43,28 @@ Examples:
   atlas config set workspace mycompany
   atlas config set username user@example.com
   atlas config set app_password              # prompts interactively
-  echo $TOKEN | atlas config set app_password  # via stdin`,
+  echo $TOKEN | atlas config set app_password  # via stdin
+  atlas config set workspace mycompany --validate`,
 		Args: cobra.RangeArgs(1, 2),
 		RunE: runConfigSet,
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			switch {
+			case len(args) == 0:
+				return config.ValidKeys(), cobra.ShellCompDirectiveNoFileComp
+			case len(args) == 1 && strings.ToLower(args[0]) == "workspace":
+				return completeWorkspaces(cmd), cobra.ShellCompDirectiveNoFileComp
+			}
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		},
 	}
+
+	cmd.Flags().Bool("validate", false, "Check that the workspace exists and is accessible before saving")
+
+	return cmd
 }
 
 func runConfigSet(cmd *cobra.Command, args []string) error {
-	key := strings.ToLower(args[0])
+	key := config.NormalizeKey(args[0])
 	if !config.IsValidKey(key) {
 		return fmt.Errorf("invalid config key: %s (valid keys: %s)", key, strings.Join(config.ValidKeys(), ", "))
 	}
@@ -61,13 +80,29 @@ func runConfigSet(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	value = config.NormalizeValue(key, value)
+
+	if err := config.ValidateValue(key, value); err != nil {
+		return err
+	}
+
+	if validate, _ := cmd.Flags().GetBool("validate"); validate && key == "workspace" {
+		client, err := newClient(cmd, bitbucket.WithWorkspace(value))
+		if err != nil {
+			return err
+		}
+		if err := validateWorkspace(client, value); err != nil {
+			return err
+		}
+	}
+
 	if err := config.Set(key, value); err != nil {
 		return err
 	}
 
 	if key == "app_password" && !config.IsEnvReference(value) {
-		fmt.Fprintln(os.Stderr, "Warning: Storing app_password directly in config file.")
-		fmt.Fprintln(os.Stderr, "Consider using ${env:ATLAS_APP_PASSWORD} syntax instead.")
+		output.LogWarn("Storing app_password directly in config file.")
+		output.LogInfo("Consider using ${env:ATLAS_APP_PASSWORD} syntax instead.")
 	}
 
 	fmt.Printf("Set %s\n", key)
@@ -89,7 +124,7 @@ func readValueInteractively(key string) (string, error) {
 		return "", fmt.Errorf("no value provided via stdin")
 	}
 
-	if !term.IsTerminal(int(os.Stdin.Fd())) {
+	if !output.IsTerminal(os.Stdin) {
 		return "", fmt.Errorf("no value provided and stdin is not a terminal")
 	}
 
@@ -118,39 +153,103 @@ func newConfigGetCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "get <key>",
 		Short: "Get a configuration value",
-		Long: `Get a configuration value. Valid keys: workspace, username, app_password.
+		Long: `Get a configuration value. Valid keys: workspace, username, app_password, default_repo,
+default_format, pr_default_state, pr_default_author, jira_site.
 
 Use --verbose to see whether the value uses an environment variable reference.`,
 		Args: cobra.ExactArgs(1),
 		RunE: runConfigGet,
 	}
+
+	cmd.Flags().Bool("json", false, "Output as JSON")
+
 	return cmd
 }
 
+func newConfigListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List all configuration values",
+		Args:  cobra.NoArgs,
+		RunE:  runConfigList,
+	}
+
+	cmd.Flags().Bool("json", false, "Output as JSON")
+
+	return cmd
+}
+
+// ConfigEntryJSON is the machine-readable form of a config value. The
+// app_password value is always masked; Set reports whether it is present.
+type ConfigEntryJSON struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	EnvRef bool   `json:"env_ref"`
+	Set    bool   `json:"set"`
+
+	raw string
+}
+
+func getConfigEntry(key string) (ConfigEntryJSON, error) {
+	rawValue, hasEnvRef, err := config.GetRaw(key)
+	if err != nil {
+		return ConfigEntryJSON{}, err
+	}
+
+	entry := ConfigEntryJSON{Key: key, Value: rawValue, EnvRef: hasEnvRef, Set: rawValue != "", raw: rawValue}
+	if key == "app_password" && entry.Set {
+		entry.Value = "****"
+	}
+	return entry, nil
+}
+
+func printConfigEntry(entry ConfigEntryJSON) {
+	switch {
+	case !entry.Set:
+		fmt.Printf("%s: (not set)\n", entry.Key)
+	case verbose && entry.EnvRef:
+		fmt.Printf("%s: %s (env reference)\n", entry.Key, entry.raw)
+	default:
+		fmt.Printf("%s: %s\n", entry.Key, entry.Value)
+	}
+}
+
 func runConfigGet(cmd *cobra.Command, args []string) error {
-	key := strings.ToLower(args[0])
+	key := config.NormalizeKey(args[0])
 	if !config.IsValidKey(key) {
 		return fmt.Errorf("invalid config key: %s (valid keys: %s)", key, strings.Join(config.ValidKeys(), ", "))
 	}
 
-	rawValue, hasEnvRef, err := config.GetRaw(key)
+	entry, err := getConfigEntry(key)
 	if err != nil {
 		return err
 	}
 
-	if rawValue == "" {
-		fmt.Printf("%s: (not set)\n", key)
-		return nil
+	if jsonOutput, _ := cmd.Flags().GetBool("json"); jsonOutput {
+		return output.WriteJSON(os.Stdout, entry)
 	}
 
-	if verbose && hasEnvRef {
-		fmt.Printf("%s: %s (env reference)\n", key, rawValue)
-	} else if key == "app_password" {
-		fmt.Printf("%s: ****\n", key)
-	} else {
-		fmt.Printf("%s: %s\n", key, rawValue)
+	printConfigEntry(entry)
+	return nil
+}
+
+func runConfigList(cmd *cobra.Command, args []string) error {
+	entries := make([]ConfigEntryJSON, 0, len(config.ValidKeys()))
+	for _, key := range config.ValidKeys() {
+		entry, err := getConfigEntry(key)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, entry)
 	}
 
+	if jsonOutput, _ := cmd.Flags().GetBool("json"); jsonOutput {
+		return output.WriteJSON(os.Stdout, entries)
+	}
+
+	for _, entry := range entries {
+		printConfigEntry(entry)
+	}
 	return nil
 }
 
@@ -165,16 +264,71 @@ func newConfigVerifyCmd() *cobra.Command {
 }
 
 func runConfigVerify(cmd *cobra.Command, args []string) error {
-	client, err := bitbucket.NewClient(bitbucket.WithNoCache(true))
+	client, err := bitbucket.NewClient(bitbucket.WithNoCache(true), bitbucket.WithContext(cmd.Context()))
 	if err != nil {
 		return fmt.Errorf("authentication failed: %w\nRun 'atlas config set username' and 'atlas config set app_password' to configure credentials", err)
 	}
 
-	user, err := client.GetCurrentUser()
+	user, scopes, err := client.GetCurrentUserWithScopes()
 	if err != nil {
 		return fmt.Errorf("authentication failed: %w", err)
 	}
 
 	fmt.Printf("Authenticated as %s (%s)\n", user.DisplayName, user.Username)
+
+	if scopes == nil {
+		output.LogVerbose("Bitbucket did not report app password scopes; skipping scope check")
+		return nil
+	}
+	if missing := bitbucket.MissingScopes(scopes); len(missing) > 0 {
+		output.LogWarn("app password appears to be missing scopes: %s\nCreate a new app password with read access to: %s",
+			strings.Join(missing, ", "), strings.Join(bitbucket.RequiredScopes, ", "))
+	}
+	return nil
+}
+
+func newConfigMigrateCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "migrate",
+		Short: "Migrate a legacy config file to the current format",
+		Long: `Rewrite a legacy config (config.json, or config.toml using old field names such as
+atlassian_email) into the canonical config.toml. The original file is backed up
+with a .bak suffix. Running migrate on an up-to-date config does nothing.`,
+		Args: cobra.NoArgs,
+		RunE: runConfigMigrate,
+	}
+}
+
+func runConfigMigrate(cmd *cobra.Command, args []string) error {
+	result, err := config.Migrate()
+	if err != nil {
+		return err
+	}
+
+	if !result.Changed {
+		fmt.Println("Config is already up to date.")
+		return nil
+	}
+
+	oldKeys := make([]string, 0, len(result.Renamed))
+	for oldKey := range result.Renamed {
+		oldKeys = append(oldKeys, oldKey)
+	}
+	sort.Strings(oldKeys)
+	for _, oldKey := range oldKeys {
+		fmt.Printf("Renamed %s -> %s\n", oldKey, result.Renamed[oldKey])
+	}
+	for _, key := range result.Dropped {
+		fmt.Printf("Dropped unknown key %s\n", key)
+	}
+	for _, backup := range result.Backups {
+		fmt.Printf("Backed up original to %s\n", backup)
+	}
+
+	configPath, err := config.ConfigPath()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Wrote %s\n", configPath)
 	return nil
 }
@@ -0,0 +1,43 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGetConfigEntry(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("ATLAS_APP_PASSWORD_FILE", "")
+	dir := filepath.Join(home, ".config", "atlas")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	content := "username = \"alice\"\napp_password = \"${env:BB_TOKEN}\"\n"
+	if err := os.WriteFile(filepath.Join(dir, "config.toml"), []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		key  string
+		want ConfigEntryJSON
+	}{
+		{"username", ConfigEntryJSON{Key: "username", Value: "alice", Set: true}},
+		{"app_password", ConfigEntryJSON{Key: "app_password", Value: "****", EnvRef: true, Set: true}},
+		{"workspace", ConfigEntryJSON{Key: "workspace"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			got, err := getConfigEntry(tt.key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got.raw = ""
+			if got != tt.want {
+				t.Errorf("getConfigEntry(%q) = %+v, want %+v", tt.key, got, tt.want)
+			}
+		})
+	}
+}
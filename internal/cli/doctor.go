@@ -0,0 +1,322 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/kabilan108/atlas/internal/bitbucket"
+	"github.com/kabilan108/atlas/internal/config"
+	"github.com/kabilan108/atlas/internal/git"
+	"github.com/kabilan108/atlas/internal/output"
+	"github.com/spf13/cobra"
+)
+
+type checkStatus int
+
+const (
+	checkPass checkStatus = iota
+	checkFail
+	checkSkip
+)
+
+type checkResult struct {
+	name   string
+	status checkStatus
+	detail string
+	hint   string
+}
+
+func newDoctorCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Diagnose configuration, credentials, and environment",
+		Long: `Run a series of checks against the local setup and print a checklist with
+actionable hints for anything that fails.
+
+Checks: config file, credentials, git executable, git remote (when inside a
+repository), cache directory, Bitbucket API access, and app password scopes.`,
+		Args: cobra.NoArgs,
+		RunE: runDoctor,
+	}
+
+	cmd.Flags().Bool("skip-api", false, "Skip checks that call the Bitbucket API")
+
+	return cmd
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	skipAPI, _ := cmd.Flags().GetBool("skip-api")
+
+	configPath, err := config.ConfigPath()
+	if err != nil {
+		return err
+	}
+
+	cfg, configResult := checkConfigFile(configPath)
+
+	// One /user call backs the credentials, API and scope checks.
+	var probe *userProbe
+	if !skipAPI && hasCredentials(cfg) {
+		probe = probeUser(cmd.Context())
+	}
+
+	results := []checkResult{
+		configResult,
+		checkCredentials(cfg, probe),
+		checkGitBinary(git.RequireGit),
+		checkGitRemote(git.InferRepository),
+		checkCacheDir(),
+	}
+
+	if skipAPI {
+		results = append(results,
+			checkResult{name: "Bitbucket API", status: checkSkip, detail: "skipped (--skip-api)"},
+			checkResult{name: "App password scopes", status: checkSkip, detail: "skipped (--skip-api)"},
+		)
+	} else {
+		results = append(results, checkAPI(probe), checkScopes(probe))
+	}
+
+	failed := 0
+	for _, r := range results {
+		printCheckResult(r)
+		if r.status == checkFail {
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("%d check(s) failed", failed)
+	}
+	return nil
+}
+
+func printCheckResult(r checkResult) {
+	glyphs := output.CurrentGlyphs()
+	mark := glyphs.Check
+	switch r.status {
+	case checkFail:
+		mark = glyphs.Cross
+	case checkSkip:
+		mark = "-"
+	}
+
+	fmt.Printf("[%s] %s: %s\n", mark, r.name, r.detail)
+	if r.hint != "" {
+		fmt.Printf("    Hint: %s\n", r.hint)
+	}
+}
+
+func checkConfigFile(path string) (*config.Config, checkResult) {
+	result := checkResult{name: "Config file"}
+
+	if _, err := os.Stat(path); err != nil {
+		result.status = checkFail
+		result.detail = fmt.Sprintf("%s not found", path)
+		result.hint = "Run 'atlas config set workspace <name>' to create it"
+		return &config.Config{}, result
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		result.status = checkFail
+		result.detail = err.Error()
+		if errors.Is(err, config.ErrMissingEnvVar) {
+			result.hint = "Export the referenced environment variable or update app_password"
+		} else {
+			result.hint = fmt.Sprintf("Fix the syntax errors in %s", path)
+		}
+		return &config.Config{}, result
+	}
+
+	result.detail = path
+	return cfg, result
+}
+
+// userProbe holds the outcome of the single /user call doctor makes.
+type userProbe struct {
+	user   *bitbucket.User
+	scopes []string
+	err    error
+}
+
+func hasCredentials(cfg *config.Config) bool {
+	return cfg.Username != "" && cfg.AppPassword != ""
+}
+
+func probeUser(ctx context.Context) *userProbe {
+	client, err := bitbucket.NewClient(bitbucket.WithNoCache(true), bitbucket.WithContext(ctx))
+	if err != nil {
+		return &userProbe{err: err}
+	}
+
+	user, scopes, err := client.GetCurrentUserWithScopes()
+	return &userProbe{user: user, scopes: scopes, err: err}
+}
+
+// credentialsRejected reports whether the probe failed because Bitbucket
+// refused the credentials, as opposed to the API being unreachable.
+func (p *userProbe) credentialsRejected() bool {
+	return errors.Is(p.err, bitbucket.ErrUnauthorized)
+}
+
+func checkCredentials(cfg *config.Config, probe *userProbe) checkResult {
+	result := checkResult{name: "Credentials"}
+
+	var missing []string
+	if cfg.Username == "" {
+		missing = append(missing, "username")
+	}
+	if cfg.AppPassword == "" {
+		missing = append(missing, "app_password")
+	}
+
+	if len(missing) > 0 {
+		result.status = checkFail
+		result.detail = "missing " + strings.Join(missing, ", ")
+		result.hint = "Run 'atlas config set username' and 'atlas config set app_password' to configure credentials"
+		return result
+	}
+
+	switch {
+	case probe == nil:
+		result.detail = fmt.Sprintf("configured for %s (not validated)", cfg.Username)
+	case probe.credentialsRejected():
+		var apiErr *bitbucket.APIError
+		errors.As(probe.err, &apiErr)
+		result.status = checkFail
+		result.detail = fmt.Sprintf("rejected for %s: %s", cfg.Username, apiErr.Message)
+		result.hint = apiErr.Hint
+	case probe.err != nil:
+		result.detail = fmt.Sprintf("configured for %s (not validated)", cfg.Username)
+	default:
+		result.detail = fmt.Sprintf("valid for %s", cfg.Username)
+	}
+	return result
+}
+
+// checkGitBinary only skips when git is missing: it is needed for
+// pr checkout, not for anything that talks to the API.
+func checkGitBinary(require func() error) checkResult {
+	result := checkResult{name: "Git executable"}
+
+	if err := require(); err != nil {
+		result.status = checkSkip
+		result.detail = "not found in PATH (only needed for pr checkout)"
+		return result
+	}
+
+	result.detail = "found"
+	return result
+}
+
+func checkGitRemote(infer func() (string, string, error)) checkResult {
+	result := checkResult{name: "Git remote"}
+
+	workspace, repo, err := infer()
+	switch {
+	case errors.Is(err, git.ErrNotGitRepository):
+		result.status = checkSkip
+		result.detail = "not inside a git repository"
+	case err != nil:
+		result.status = checkFail
+		result.detail = err.Error()
+		result.hint = "Set origin to a bitbucket.org URL or pass --repo to commands"
+	default:
+		result.detail = fmt.Sprintf("%s/%s", workspace, repo)
+	}
+
+	return result
+}
+
+func checkCacheDir() checkResult {
+	result := checkResult{name: "Cache directory"}
+
+	dir, err := bitbucket.CacheDir()
+	if err != nil {
+		result.status = checkFail
+		result.detail = err.Error()
+		return result
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		result.status = checkFail
+		result.detail = err.Error()
+		result.hint = "Use --no-cache or fix permissions on the cache directory"
+		return result
+	}
+
+	f, err := os.CreateTemp(dir, ".doctor-*")
+	if err != nil {
+		result.status = checkFail
+		result.detail = fmt.Sprintf("%s is not writable", dir)
+		result.hint = "Use --no-cache or fix permissions on the cache directory"
+		return result
+	}
+	f.Close()
+	os.Remove(f.Name())
+
+	result.detail = dir
+	return result
+}
+
+func checkAPI(probe *userProbe) checkResult {
+	result := checkResult{name: "Bitbucket API"}
+
+	switch {
+	case probe == nil:
+		result.status = checkSkip
+		result.detail = "skipped (no credentials)"
+		return result
+	case probe.credentialsRejected():
+		result.detail = "reachable"
+		return result
+	case probe.err != nil:
+		result.status = checkFail
+		var apiErr *bitbucket.APIError
+		if errors.As(probe.err, &apiErr) {
+			result.detail = fmt.Sprintf("%s: %s", apiErr.Resource, apiErr.Message)
+			result.hint = apiErr.Hint
+		} else {
+			result.detail = fmt.Sprintf("unreachable: %s", probe.err)
+			result.hint = "Check your network connection and proxy settings"
+		}
+		return result
+	}
+
+	result.detail = fmt.Sprintf("authenticated as %s (%s)", probe.user.DisplayName, probe.user.Username)
+	return result
+}
+
+func checkScopes(probe *userProbe) checkResult {
+	result := checkResult{name: "App password scopes"}
+
+	switch {
+	case probe == nil:
+		result.status = checkSkip
+		result.detail = "skipped (no credentials)"
+	case probe.credentialsRejected():
+		result.status = checkSkip
+		result.detail = "skipped (credentials rejected)"
+	case probe.err != nil:
+		result.status = checkSkip
+		result.detail = "skipped (API unreachable)"
+	case probe.scopes == nil:
+		result.status = checkSkip
+		result.detail = "not reported by Bitbucket"
+	default:
+		if missing := bitbucket.MissingScopes(probe.scopes); len(missing) > 0 {
+			result.status = checkFail
+			result.detail = "missing " + strings.Join(missing, ", ")
+			result.hint = fmt.Sprintf("Create a new app password with read access to: %s", strings.Join(bitbucket.RequiredScopes, ", "))
+		} else {
+			result.detail = strings.Join(probe.scopes, ", ")
+		}
+	}
+
+	return result
+}
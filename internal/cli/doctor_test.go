@@ -0,0 +1,150 @@
+package cli
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/kabilan108/atlas/internal/bitbucket"
+	"github.com/kabilan108/atlas/internal/config"
+	"github.com/kabilan108/atlas/internal/git"
+)
+
+func TestDoctorProbeChecks(t *testing.T) {
+	cfg := &config.Config{Username: "alice", AppPassword: "secret"}
+	user := &bitbucket.User{Username: "alice", DisplayName: "Alice"}
+
+	tests := []struct {
+		name        string
+		cfg         *config.Config
+		probe       *userProbe
+		credentials checkStatus
+		api         checkStatus
+		scopes      checkStatus
+		detail      string
+	}{
+		{
+			name:        "missing credentials",
+			cfg:         &config.Config{Username: "alice"},
+			credentials: checkFail,
+			api:         checkSkip,
+			scopes:      checkSkip,
+			detail:      "missing app_password",
+		},
+		{
+			name:        "api skipped",
+			cfg:         cfg,
+			credentials: checkPass,
+			api:         checkSkip,
+			scopes:      checkSkip,
+			detail:      "not validated",
+		},
+		{
+			name:        "valid with scopes",
+			cfg:         cfg,
+			probe:       &userProbe{user: user, scopes: []string{"account", "repository:write", "pullrequest"}},
+			credentials: checkPass,
+			api:         checkPass,
+			scopes:      checkPass,
+			detail:      "valid for alice",
+		},
+		{
+			name:        "missing scopes",
+			cfg:         cfg,
+			probe:       &userProbe{user: user, scopes: []string{"account"}},
+			credentials: checkPass,
+			api:         checkPass,
+			scopes:      checkFail,
+		},
+		{
+			name:        "scopes not reported",
+			cfg:         cfg,
+			probe:       &userProbe{user: user},
+			credentials: checkPass,
+			api:         checkPass,
+			scopes:      checkSkip,
+		},
+		{
+			name:        "credentials rejected",
+			cfg:         cfg,
+			probe:       &userProbe{err: bitbucket.NewExpiredTokenError()},
+			credentials: checkFail,
+			api:         checkPass,
+			scopes:      checkSkip,
+			detail:      "app password expired or revoked",
+		},
+		{
+			name:        "server error",
+			cfg:         cfg,
+			probe:       &userProbe{err: bitbucket.NewServerError(503, "unavailable")},
+			credentials: checkPass,
+			api:         checkFail,
+			scopes:      checkSkip,
+		},
+		{
+			name:        "unreachable",
+			cfg:         cfg,
+			probe:       &userProbe{err: errors.New("dial tcp: no such host")},
+			credentials: checkPass,
+			api:         checkFail,
+			scopes:      checkSkip,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			probe := tt.probe
+			if !hasCredentials(tt.cfg) {
+				probe = nil
+			}
+
+			creds := checkCredentials(tt.cfg, probe)
+			if creds.status != tt.credentials {
+				t.Errorf("credentials status = %v, want %v (%s)", creds.status, tt.credentials, creds.detail)
+			}
+			if !strings.Contains(creds.detail, tt.detail) {
+				t.Errorf("credentials detail = %q, want it to contain %q", creds.detail, tt.detail)
+			}
+			if api := checkAPI(probe); api.status != tt.api {
+				t.Errorf("API status = %v, want %v (%s)", api.status, tt.api, api.detail)
+			}
+			if scopes := checkScopes(probe); scopes.status != tt.scopes {
+				t.Errorf("scopes status = %v, want %v (%s)", scopes.status, tt.scopes, scopes.detail)
+			}
+		})
+	}
+}
+
+func TestCheckGitBinary(t *testing.T) {
+	if r := checkGitBinary(func() error { return nil }); r.status != checkPass {
+		t.Errorf("status = %v, want pass", r.status)
+	}
+	if r := checkGitBinary(func() error { return errors.New("not found") }); r.status != checkSkip {
+		t.Errorf("status = %v, want skip when git is missing", r.status)
+	}
+}
+
+func TestCheckGitRemote(t *testing.T) {
+	tests := []struct {
+		name   string
+		infer  func() (string, string, error)
+		status checkStatus
+		detail string
+	}{
+		{"bitbucket remote", func() (string, string, error) { return "ws", "repo", nil }, checkPass, "ws/repo"},
+		{"not a repository", func() (string, string, error) { return "", "", git.ErrNotGitRepository }, checkSkip, "not inside"},
+		{"foreign remote", func() (string, string, error) { return "", "", errors.New("not a Bitbucket URL") }, checkFail, "not a Bitbucket URL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := checkGitRemote(tt.infer)
+			if r.status != tt.status {
+				t.Errorf("status = %v, want %v", r.status, tt.status)
+			}
+			if !strings.Contains(r.detail, tt.detail) {
+				t.Errorf("detail = %q, want it to contain %q", r.detail, tt.detail)
+			}
+		})
+	}
+}
@@ -1,9 +1,19 @@
 package cli
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/kabilan108/atlas/internal/bitbucket"
 	"github.com/kabilan108/atlas/internal/config"
@@ -12,6 +22,21 @@ import (
 	"github.com/spf13/cobra"
 )
 
+const maxPRBatchSize = 50
+
+var (
+	// prBatchConcurrency caps how many PRs a batch view fetches at once.
+	prBatchConcurrency = 4
+
+	// canPrompt reports whether pr view may ask the user to pick a PR.
+	canPrompt = func() bool {
+		return output.IsTerminal(os.Stdin) && output.IsTerminal(os.Stdout)
+	}
+
+	commitHashPattern = regexp.MustCompile(`^[0-9a-f]{7,40}$`)
+	prIDListPattern   = regexp.MustCompile(`^#?\d+(-#?\d+)?(,\s*#?\d+(-#?\d+)?)*$`)
+)
+
 func newPRCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "pr",
@@ -21,6 +46,10 @@ func newPRCmd() *cobra.Command {
 	cmd.AddCommand(newPRListCmd())
 	cmd.AddCommand(newPRViewCmd())
 	cmd.AddCommand(newPRCheckoutCmd())
+	cmd.AddCommand(newPRDiffCmd())
+	cmd.AddCommand(newPRReviewersCmd())
+	cmd.AddCommand(newPRCommentCmd())
+	cmd.AddCommand(newPRReportCmd())
 
 	return cmd
 }
@@ -32,12 +61,15 @@ func newPRListCmd() *cobra.Command {
 		RunE:  runPRList,
 	}
 
-	cmd.Flags().String("repo", "", "Target repository")
+	cmd.Flags().String("repo", "", "Target repository (repo or workspace/repo)")
 	cmd.Flags().Bool("all", false, "List PRs across all repos in workspace")
-	cmd.Flags().String("state", "open", "Filter by state: open, merged, declined, superseded")
-	cmd.Flags().String("author", "", "Filter by author username")
-	cmd.Flags().String("reviewer", "", "Filter by reviewer username")
+	cmd.Flags().String("state", "open", "Filter by state: open, merged, declined, superseded (comma-separated for several)")
+	cmd.Flags().String("author", "", "Filter by author username (use 'me' or '@me' for yourself)")
+	cmd.Flags().String("reviewer", "", "Filter by reviewer username (use 'me' or '@me' for yourself)")
 	cmd.Flags().Bool("json", false, "Output as JSON")
+	cmd.Flags().Bool("count", false, "Print only the number of matching PRs")
+	cmd.Flags().Int("concurrency", 4, "Number of repositories to fetch in parallel (with --all)")
+	cmd.Flags().Int("limit", 0, "Maximum number of PRs to show (0 for no limit)")
 
 	return cmd
 }
@@ -48,59 +80,106 @@ func runPRList(cmd *cobra.Command, args []string) error {
 	state, _ := cmd.Flags().GetString("state")
 	author, _ := cmd.Flags().GetString("author")
 	reviewer, _ := cmd.Flags().GetString("reviewer")
-	jsonOutput, _ := cmd.Flags().GetBool("json")
+	countOnly, _ := cmd.Flags().GetBool("count")
+	concurrency, _ := cmd.Flags().GetInt("concurrency")
+	limit, _ := cmd.Flags().GetInt("limit")
 
 	cfg, err := config.Load()
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 
-	workspace := cfg.Workspace
-	repo := repoFlag
+	jsonOutput := useJSONOutput(cmd, cfg)
 
-	if !allRepos && repo == "" {
-		inferredWS, inferredRepo, err := git.InferRepository()
-		if err != nil {
-			return fmt.Errorf("could not infer repository: %w\nUse --repo to specify or --all for all repos", err)
-		}
-		if workspace == "" {
-			workspace = inferredWS
-		}
-		repo = inferredRepo
-		if verbose {
-			fmt.Fprintf(os.Stderr, "Using repository: %s/%s\n", workspace, repo)
-		}
+	if !cmd.Flags().Changed("state") && cfg.PRDefaultState != "" {
+		state = cfg.PRDefaultState
+	}
+	if !cmd.Flags().Changed("author") && cfg.PRDefaultAuthor != "" {
+		author = cfg.PRDefaultAuthor
 	}
 
-	if workspace == "" {
-		return fmt.Errorf("workspace not configured. Run 'atlas config set workspace <name>' or use --all")
+	var workspace, repo string
+	if allRepos {
+		workspace, err = resolveWorkspace("", cfg, true)
+	} else {
+		workspace, repo, err = resolveRepository(repoFlag, cfg)
+	}
+	if err != nil {
+		return err
 	}
 
-	client, err := bitbucket.NewClient(
-		bitbucket.WithNoCache(noCache),
-	)
+	progress := output.NewProgress(os.Stderr, "repositories")
+	defer progress.Done()
+
+	client, err := newClient(cmd, bitbucket.WithWorkspace(workspace), bitbucket.WithProgress(progress.Update), bitbucket.WithConcurrency(concurrency))
+	if err != nil {
+		return err
+	}
+
+	author, _, err = resolveUser(client, author)
+	if err != nil {
+		return err
+	}
+
+	reviewer, reviewerUUID, err := resolveUser(client, reviewer)
+	if err != nil {
+		return err
+	}
+	if reviewerUUID == "" {
+		reviewerUUID = reviewer
+	}
+
+	states, err := parsePRStates(state)
 	if err != nil {
 		return err
 	}
 
 	opts := &bitbucket.PRListOptions{
-		State:    strings.ToUpper(state),
+		States:   states,
 		Author:   author,
 		Reviewer: reviewer,
+		Limit:    limit,
+	}
+
+	if countOnly && !allRepos {
+		count, err := client.CountPullRequests(workspace, repo, opts)
+		if err != nil {
+			return err
+		}
+		fmt.Println(count)
+		return nil
 	}
 
 	var prs []bitbucket.PullRequest
-	if allRepos {
+	if allRepos && reviewer != "" {
+		prs, err = client.ListPullRequestsForReviewer(workspace, reviewerUUID, opts)
+	} else if allRepos {
 		prs, err = client.ListAllPullRequests(workspace, opts)
 	} else {
 		prs, err = client.ListPullRequests(workspace, repo, opts)
 	}
-	if err != nil {
+	progress.Done()
+
+	var partialErr *bitbucket.PartialError
+	if errors.As(err, &partialErr) {
+		for _, repoErr := range partialErr.Errors {
+			output.LogWarn("skipped %s", repoErr)
+		}
+	} else if err != nil {
 		return err
 	}
 
+	if limit > 0 && len(prs) > limit {
+		prs = prs[:limit]
+	}
+
+	if countOnly {
+		fmt.Println(len(prs))
+		return nil
+	}
+
 	if jsonOutput {
-		return output.WriteJSON(os.Stdout, prs)
+		return output.WriteJSON(os.Stdout, newPRListJSON(prs))
 	}
 
 	if len(prs) == 0 {
@@ -139,22 +218,114 @@ func runPRList(cmd *cobra.Command, args []string) error {
 	return tw.Flush()
 }
 
+func parsePRStates(spec string) ([]string, error) {
+	var states []string
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(spec, ",") {
+		state := strings.ToLower(strings.TrimSpace(part))
+		if state == "" {
+			continue
+		}
+		if !config.IsValidPRState(state) {
+			return nil, fmt.Errorf("invalid state: %s (valid states: open, merged, declined, superseded)", state)
+		}
+		if seen[state] {
+			continue
+		}
+		seen[state] = true
+		states = append(states, strings.ToUpper(state))
+	}
+	return states, nil
+}
+
 func newPRViewCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "view <id|branch>",
+		Use:   "view <id|branch|commit|id-list|->",
 		Short: "View a pull request",
-		Args:  cobra.ExactArgs(1),
-		RunE:  runPRView,
+		Long: `View a pull request by ID, branch name, or commit hash.
+
+Several PRs can be viewed at once with a comma-separated list and/or ranges
+of IDs, e.g. "40,42,45-48". They are fetched concurrently and printed in order.
+
+Pass "-" to read a Bitbucket pull request webhook payload from stdin and view
+the PR it refers to, e.g. in a Pipelines step: cat payload.json | atlas pr view -
+
+Without an argument on a terminal, the open PRs are listed and you are
+prompted to pick one by number. Elsewhere the argument is required.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: runPRView,
 	}
 
-	cmd.Flags().String("repo", "", "Target repository")
+	cmd.Flags().String("repo", "", "Target repository (repo or workspace/repo)")
 	cmd.Flags().Bool("comments", false, "Include all comments")
 	cmd.Flags().Bool("all", false, "Include resolved comments (only with --comments)")
 	cmd.Flags().Bool("json", false, "Output as JSON")
+	cmd.Flags().Bool("raw", false, "Output the unmodified API response as JSON")
+	cmd.Flags().Bool("web", false, "Print only the PR's URL")
+	cmd.Flags().Bool("llm", false, "Bundle the rendered PRs into one prompt-ready block with an index")
+	cmd.Flags().Bool("commit", false, "Treat the argument as a commit hash")
+	cmd.Flags().String("output-dir", "", "Write the PR to a file in this directory instead of stdout")
+	cmd.Flags().Bool("frontmatter", false, "Prefix markdown output with YAML front matter")
+	cmd.Flags().String("meta-fields", "", "Comma-separated front matter keys to include, in order (default: all)")
+	cmd.Flags().Int("context-lines", output.DefaultContextLines, "Lines of diff context around inline comments (only with --comments)")
+	cmd.Flags().String("comments-sort", output.CommentSortPath, "Order comment threads by created, updated, or path (only with --comments)")
+	cmd.Flags().String("group-by", output.CommentGroupFile, "Section comments by file, author, or none (only with --comments)")
+	cmd.Flags().Int("max-content-bytes", 0, "Truncate each rendered PR to this many bytes, with a marker (0 for no limit)")
+	cmd.Flags().Bool("approval-times", false, "Show when each reviewer approved (fetches PR activity)")
+	cmd.Flags().Bool("readiness", false, "Show a merge readiness verdict for open PRs (fetches tasks and build statuses)")
 
 	return cmd
 }
 
+// prListSchemaVersion must be bumped whenever a PRListItem field is renamed,
+// removed or changes meaning. Adding fields is backwards compatible.
+const prListSchemaVersion = 1
+
+// PRListJSON is the stable `pr list --json` envelope. It is decoupled from the
+// API types so upstream changes don't leak into scripts.
+type PRListJSON struct {
+	SchemaVersion int          `json:"schema_version"`
+	Items         []PRListItem `json:"items"`
+}
+
+type PRListItem struct {
+	ID                int       `json:"id"`
+	Title             string    `json:"title"`
+	State             string    `json:"state"`
+	Author            string    `json:"author"`
+	AuthorUsername    string    `json:"author_username"`
+	Repository        string    `json:"repository"`
+	SourceBranch      string    `json:"source_branch"`
+	DestinationBranch string    `json:"destination_branch"`
+	CommentCount      int       `json:"comment_count"`
+	TaskCount         int       `json:"task_count"`
+	CreatedOn         time.Time `json:"created_on"`
+	UpdatedOn         time.Time `json:"updated_on"`
+	URL               string    `json:"url"`
+}
+
+func newPRListJSON(prs []bitbucket.PullRequest) PRListJSON {
+	items := make([]PRListItem, 0, len(prs))
+	for _, pr := range prs {
+		items = append(items, PRListItem{
+			ID:                pr.ID,
+			Title:             pr.Title,
+			State:             pr.State,
+			Author:            pr.Author.DisplayName,
+			AuthorUsername:    pr.Author.Username,
+			Repository:        pr.Destination.Repository.FullName,
+			SourceBranch:      pr.Source.Branch.Name,
+			DestinationBranch: pr.Destination.Branch.Name,
+			CommentCount:      pr.CommentCount,
+			TaskCount:         pr.TaskCount,
+			CreatedOn:         pr.CreatedOn,
+			UpdatedOn:         pr.UpdatedOn,
+			URL:               pr.Links.HTML.Href,
+		})
+	}
+	return PRListJSON{SchemaVersion: prListSchemaVersion, Items: items}
+}
+
 type PRViewJSON struct {
 	*bitbucket.PullRequest
 	Comments []bitbucket.Comment `json:"comments,omitempty"`
@@ -164,86 +335,502 @@ func runPRView(cmd *cobra.Command, args []string) error {
 	repoFlag, _ := cmd.Flags().GetString("repo")
 	showComments, _ := cmd.Flags().GetBool("comments")
 	includeResolved, _ := cmd.Flags().GetBool("all")
-	jsonOutput, _ := cmd.Flags().GetBool("json")
+	rawOutput, _ := cmd.Flags().GetBool("raw")
+	webOutput, _ := cmd.Flags().GetBool("web")
+	llmOutput, _ := cmd.Flags().GetBool("llm")
+	byCommit, _ := cmd.Flags().GetBool("commit")
+	outputDir, _ := cmd.Flags().GetString("output-dir")
+	frontmatter, _ := cmd.Flags().GetBool("frontmatter")
+	contextLines, _ := cmd.Flags().GetInt("context-lines")
+	metaFieldsFlag, _ := cmd.Flags().GetString("meta-fields")
+	approvalTimes, _ := cmd.Flags().GetBool("approval-times")
+	readiness, _ := cmd.Flags().GetBool("readiness")
+	maxContentBytes, _ := cmd.Flags().GetInt("max-content-bytes")
+	commentsSort, _ := cmd.Flags().GetString("comments-sort")
+	groupBy, _ := cmd.Flags().GetString("group-by")
+
+	metaFields, err := output.ParseMetaFields(metaFieldsFlag)
+	if err != nil {
+		return err
+	}
+	if len(metaFields) > 0 {
+		frontmatter = true
+	}
+
+	commentOrder, err := output.ParseCommentOrder(commentsSort, groupBy)
+	if err != nil {
+		return err
+	}
 
 	cfg, err := config.Load()
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 
-	workspace := cfg.Workspace
-	repo := repoFlag
+	jsonOutput := useJSONOutput(cmd, cfg)
+
+	var ref string
+	if len(args) > 0 {
+		ref = args[0]
+	} else if byCommit || !canPrompt() {
+		return fmt.Errorf("a PR id, branch, or commit is required (interactive selection needs a terminal)")
+	}
 
-	if repo == "" {
-		inferredWS, inferredRepo, err := git.InferRepository()
+	var workspace, repo string
+	if ref == "-" {
+		hook, err := readWebhook(os.Stdin)
 		if err != nil {
-			return fmt.Errorf("could not infer repository: %w\nUse --repo to specify", err)
+			return err
 		}
-		if workspace == "" {
-			workspace = inferredWS
+		workspace, repo, ref = hook.Workspace, hook.Repo, strconv.Itoa(hook.ID)
+		output.LogVerbose("Using PR #%s in %s/%s from webhook payload", ref, workspace, repo)
+	} else {
+		workspace, repo, err = resolveRepository(repoFlag, cfg)
+		if err != nil {
+			return err
 		}
-		repo = inferredRepo
-		if verbose {
-			fmt.Fprintf(os.Stderr, "Using repository: %s/%s\n", workspace, repo)
+	}
+
+	client, err := newClient(cmd, bitbucket.WithWorkspace(workspace))
+	if err != nil {
+		return err
+	}
+
+	opts := prViewOptions{
+		raw:             rawOutput,
+		web:             webOutput,
+		llm:             llmOutput,
+		json:            jsonOutput,
+		comments:        showComments,
+		includeResolved: includeResolved,
+		frontmatter:     frontmatter,
+		contextLines:    contextLines,
+		commentOrder:    commentOrder,
+		metaFields:      metaFields,
+		approvalTimes:   approvalTimes,
+		readiness:       readiness,
+		jiraSite:        cfg.JiraSite,
+		maxContentBytes: maxContentBytes,
+	}
+
+	if ref == "" {
+		id, err := pickPR(client, workspace, repo, os.Stdin, os.Stderr)
+		if err != nil {
+			return err
 		}
+		ref = strconv.Itoa(id)
 	}
 
-	if workspace == "" {
-		return fmt.Errorf("workspace not configured. Run 'atlas config set workspace <name>'")
+	if !byCommit && strings.ContainsAny(ref, ",-") && prIDListPattern.MatchString(ref) {
+		ids, err := parseIDRange(ref)
+		if err != nil {
+			return err
+		}
+		return viewPRBatch(os.Stdout, client, workspace, repo, ids, opts, outputDir)
 	}
 
-	client, err := bitbucket.NewClient(
-		bitbucket.WithNoCache(noCache),
-	)
+	var pr *bitbucket.PullRequest
+	if byCommit {
+		pr, err = client.FindPullRequestByCommit(workspace, repo, ref)
+	} else {
+		pr, err = resolvePR(client, workspace, repo, ref)
+	}
 	if err != nil {
 		return err
 	}
 
-	pr, err := resolvePR(client, workspace, repo, args[0])
+	if opts.llm || opts.truncates() {
+		return viewPRBatch(os.Stdout, client, workspace, repo, []int{pr.ID}, opts, outputDir)
+	}
+
+	if outputDir == "" {
+		pager := output.NewPager(os.Stdout, !noPager && !opts.json && !opts.raw && !opts.web)
+		if err := writePRView(pager.Writer(), client, workspace, repo, pr, opts); err != nil {
+			return err
+		}
+		return pager.Close()
+	}
+
+	f, err := createPRFile(outputDir, pr, opts)
 	if err != nil {
 		return err
 	}
+	defer f.Close()
 
-	if jsonOutput {
+	if err := writePRView(f, client, workspace, repo, pr, opts); err != nil {
+		return err
+	}
+	output.LogInfo("Wrote %s", f.Name())
+	return nil
+}
+
+// pickPR lists the open PRs on out and reads the chosen number from in.
+func pickPR(client *bitbucket.Client, workspace, repo string, in io.Reader, out io.Writer) (int, error) {
+	prs, err := client.ListPullRequests(workspace, repo, &bitbucket.PRListOptions{States: []string{"OPEN"}})
+	if err != nil {
+		return 0, err
+	}
+	if len(prs) == 0 {
+		return 0, fmt.Errorf("no open pull requests in %s/%s", workspace, repo)
+	}
+	return selectPR(prs, in, out)
+}
+
+// selectPR prints prs as a numbered menu and returns the chosen PR's ID. It
+// keeps asking until the answer is valid; an empty answer or EOF cancels.
+func selectPR(prs []bitbucket.PullRequest, in io.Reader, out io.Writer) (int, error) {
+	for i, pr := range prs {
+		fmt.Fprintf(out, "%3d. #%d %s (%s)\n", i+1, pr.ID, output.Truncate(pr.Title, 60), pr.Author.DisplayName)
+	}
+
+	scanner := bufio.NewScanner(in)
+	for {
+		fmt.Fprintf(out, "Select a PR [1-%d]: ", len(prs))
+		if !scanner.Scan() {
+			fmt.Fprintln(out)
+			if err := scanner.Err(); err != nil {
+				return 0, fmt.Errorf("failed to read selection: %w", err)
+			}
+			return 0, fmt.Errorf("no PR selected")
+		}
+
+		answer := strings.TrimSpace(scanner.Text())
+		if answer == "" {
+			return 0, fmt.Errorf("no PR selected")
+		}
+		n, err := strconv.Atoi(answer)
+		if err != nil || n < 1 || n > len(prs) {
+			fmt.Fprintf(out, "Enter a number between 1 and %d.\n", len(prs))
+			continue
+		}
+		return prs[n-1].ID, nil
+	}
+}
+
+// fetchMergeReadiness loads the tasks and build statuses behind the merge
+// verdict. The tasks are returned too so --comments can reuse them; they are
+// nil when either fetch failed.
+func fetchMergeReadiness(client *bitbucket.Client, workspace, repo string, pr *bitbucket.PullRequest) (*bitbucket.MergeReadiness, []bitbucket.Task) {
+	var (
+		tasks              []bitbucket.Task
+		statuses           []bitbucket.CommitStatus
+		taskErr, statusErr error
+		wg                 sync.WaitGroup
+	)
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		tasks, taskErr = client.ListPullRequestTasks(workspace, repo, pr.ID)
+	}()
+	go func() {
+		defer wg.Done()
+		statuses, statusErr = client.ListPullRequestStatuses(workspace, repo, pr.ID)
+	}()
+	wg.Wait()
+
+	if err := errors.Join(taskErr, statusErr); err != nil {
+		output.LogVerbose("Skipping merge readiness for PR #%d: %s", pr.ID, err)
+		return nil, nil
+	}
+
+	readiness := pr.MergeReadiness(tasks, statuses)
+	return &readiness, tasks
+}
+
+func readWebhook(r io.Reader) (*bitbucket.WebhookPullRequest, error) {
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read webhook payload from stdin: %w", err)
+	}
+	return bitbucket.ParsePullRequestWebhook(body)
+}
+
+// viewPRBatch renders the PRs in ids to w, or to files under outputDir when it
+// is set.
+func viewPRBatch(w io.Writer, client *bitbucket.Client, workspace, repo string, ids []int, opts prViewOptions, outputDir string) error {
+	prs := make([]*bitbucket.PullRequest, len(ids))
+	docs := make([]bytes.Buffer, len(ids))
+	errs := make([]error, len(ids))
+
+	sem := make(chan struct{}, prBatchConcurrency)
+	var wg sync.WaitGroup
+	for i, id := range ids {
+		wg.Add(1)
+		go func(i, id int) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			pr, err := client.GetPullRequest(workspace, repo, id)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			prs[i] = pr
+			errs[i] = writePRView(&docs[i], client, workspace, repo, pr, opts)
+			if errs[i] == nil && opts.truncates() {
+				content := output.TruncateContent(docs[i].String(), opts.maxContentBytes)
+				docs[i].Reset()
+				docs[i].WriteString(content)
+			}
+		}(i, id)
+	}
+	wg.Wait()
+
+	// Documents are rendered into buffers and emitted with a single write
+	// each, so an interrupted batch still flushes every completed document
+	// and never a truncated one.
+	failed := 0
+	cancelled := 0
+	written := 0
+	var bundle []output.LLMDocument
+	for i, id := range ids {
+		if errors.Is(errs[i], context.Canceled) {
+			cancelled++
+			continue
+		}
+		if errs[i] != nil {
+			output.LogError("PR #%d: %s", id, errs[i])
+			failed++
+			continue
+		}
+
+		if opts.llm {
+			bundle = append(bundle, output.LLMDocument{
+				Title:   fmt.Sprintf("PR #%d: %s", prs[i].ID, prs[i].Title),
+				Source:  prs[i].Links.HTML.Href,
+				Content: docs[i].String(),
+			})
+			continue
+		}
+
+		if outputDir != "" {
+			f, err := createPRFile(outputDir, prs[i], opts)
+			if err != nil {
+				return err
+			}
+			_, err = docs[i].WriteTo(f)
+			f.Close()
+			if err != nil {
+				return fmt.Errorf("failed to write %s: %w", f.Name(), err)
+			}
+			output.LogInfo("Wrote %s", f.Name())
+			continue
+		}
+
+		if written > 0 && !opts.json && !opts.raw && !opts.web {
+			fmt.Fprintln(w)
+		}
+		if _, err := docs[i].WriteTo(w); err != nil {
+			return err
+		}
+		written++
+	}
+
+	if len(bundle) > 0 {
+		if err := writeLLMBundle(w, outputDir, bundle); err != nil {
+			return err
+		}
+	}
+
+	if cancelled > 0 {
+		return fmt.Errorf("%d of %d pull requests not fetched: %w", cancelled, len(ids), context.Canceled)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d pull requests could not be fetched", failed, len(ids))
+	}
+	return nil
+}
+
+func writeLLMBundle(w io.Writer, outputDir string, bundle []output.LLMDocument) error {
+	if outputDir == "" {
+		return output.WriteLLMBundle(w, bundle)
+	}
+
+	f, err := output.CreateDocumentFile(outputDir, "pull-requests", ".md")
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := output.WriteLLMBundle(f, bundle); err != nil {
+		return fmt.Errorf("failed to write %s: %w", f.Name(), err)
+	}
+	output.LogInfo("Wrote %s", f.Name())
+	return nil
+}
+
+func createPRFile(outputDir string, pr *bitbucket.PullRequest, opts prViewOptions) (*os.File, error) {
+	ext := ".md"
+	if opts.raw || opts.json {
+		ext = ".json"
+	}
+	return output.CreateDocumentFile(outputDir, fmt.Sprintf("%d-%s", pr.ID, pr.Title), ext)
+}
+
+func parseIDRange(spec string) ([]int, error) {
+	var ids []int
+	seen := make(map[int]bool)
+
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimPrefix(strings.TrimSpace(part), "#")
+		if part == "" {
+			return nil, fmt.Errorf("invalid PR list %q: empty entry", spec)
+		}
+
+		start, end := part, part
+		if bounds := strings.SplitN(part, "-", 2); len(bounds) == 2 {
+			start, end = bounds[0], strings.TrimPrefix(bounds[1], "#")
+		}
+
+		first, err := strconv.Atoi(start)
+		if err != nil {
+			return nil, fmt.Errorf("invalid PR ID %q in %q", start, spec)
+		}
+		last, err := strconv.Atoi(end)
+		if err != nil {
+			return nil, fmt.Errorf("invalid PR ID %q in %q", end, spec)
+		}
+		if first <= 0 || last < first {
+			return nil, fmt.Errorf("invalid PR range %q in %q", part, spec)
+		}
+
+		for id := first; id <= last; id++ {
+			if seen[id] {
+				continue
+			}
+			if len(ids) >= maxPRBatchSize {
+				return nil, fmt.Errorf("PR list %q expands to more than %d pull requests", spec, maxPRBatchSize)
+			}
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	return ids, nil
+}
+
+type prViewOptions struct {
+	raw             bool
+	web             bool
+	llm             bool
+	json            bool
+	comments        bool
+	includeResolved bool
+	frontmatter     bool
+	contextLines    int
+	commentOrder    output.CommentOrder
+	metaFields      []string
+	approvalTimes   bool
+	readiness       bool
+	jiraSite        string
+	maxContentBytes int
+}
+
+// truncates reports whether rendered markdown is capped; JSON is never cut so
+// it stays parseable.
+func (o prViewOptions) truncates() bool {
+	return o.maxContentBytes > 0 && !o.json && !o.raw
+}
+
+func writePRView(w io.Writer, client *bitbucket.Client, workspace, repo string, pr *bitbucket.PullRequest, opts prViewOptions) error {
+	if opts.web {
+		_, err := fmt.Fprintln(w, pr.Links.HTML.Href)
+		return err
+	}
+
+	if opts.raw {
+		data, err := client.GetPullRequestRaw(workspace, repo, pr.ID)
+		if err != nil {
+			return err
+		}
+		return output.WriteRawJSON(w, data)
+	}
+
+	if opts.json {
 		result := PRViewJSON{PullRequest: pr}
 		comments, err := client.ListPullRequestComments(workspace, repo, pr.ID)
 		if err != nil {
 			return fmt.Errorf("failed to fetch comments: %w", err)
 		}
 		result.Comments = comments
-		return output.WriteJSON(os.Stdout, result)
+		return output.WriteJSON(w, result)
 	}
 
-	mdWriter := output.NewPRMarkdownWriter(os.Stdout)
+	mdWriter := output.NewPRMarkdownWriter(w)
+	mdWriter.SetFrontmatter(opts.frontmatter)
+	mdWriter.SetMetaFields(opts.metaFields)
+	mdWriter.SetJiraSite(opts.jiraSite)
+	var readiness *bitbucket.MergeReadiness
+	var tasks []bitbucket.Task
+	if opts.readiness && pr.State == "OPEN" {
+		readiness, tasks = fetchMergeReadiness(client, workspace, repo, pr)
+		mdWriter.SetMergeReadiness(readiness)
+	}
+	if opts.approvalTimes {
+		approvals, err := client.ListPullRequestApprovals(workspace, repo, pr.ID)
+		if err != nil {
+			output.LogWarn("Skipping approval times for PR #%d: %s", pr.ID, err)
+		}
+		mdWriter.SetApprovals(approvals)
+	}
 	if err := mdWriter.WritePR(pr); err != nil {
 		return err
 	}
 
-	if showComments {
-		comments, err := client.ListPullRequestComments(workspace, repo, pr.ID)
-		if err != nil {
-			return fmt.Errorf("failed to fetch comments: %w", err)
+	if opts.comments {
+		// Comments, diff and tasks are independent, so fetch them together
+		var (
+			comments                      []bitbucket.Comment
+			diff                          []byte
+			commentsErr, diffErr, taskErr error
+			wg                            sync.WaitGroup
+		)
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			comments, commentsErr = client.ListPullRequestComments(workspace, repo, pr.ID)
+		}()
+		go func() {
+			defer wg.Done()
+			diff, diffErr = client.GetPullRequestDiff(workspace, repo, pr.ID)
+		}()
+		// Tasks already loaded for --readiness are reused
+		if readiness == nil {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				tasks, taskErr = client.ListPullRequestTasks(workspace, repo, pr.ID)
+			}()
 		}
+		wg.Wait()
 
-		diff, _ := client.GetPullRequestDiff(workspace, repo, pr.ID)
+		if commentsErr != nil {
+			return fmt.Errorf("failed to fetch comments: %w", commentsErr)
+		}
+		if taskErr != nil {
+			return fmt.Errorf("failed to fetch tasks: %w", taskErr)
+		}
+		// The diff only adds code context to inline comments, which still
+		// render without it
+		if diffErr != nil {
+			output.LogWarn("Skipping diff context for PR #%d: %s", pr.ID, diffErr)
+		}
 
-		fmt.Println()
-		commentWriter := output.NewCommentWriter(os.Stdout, pr.Author.UUID)
+		fmt.Fprintln(w)
+		commentWriter := output.NewCommentWriter(w, pr.Author.UUID)
+		commentWriter.SetContextLines(opts.contextLines)
+		commentWriter.SetOrder(opts.commentOrder)
 		if len(diff) > 0 {
 			commentWriter.SetDiff(diff)
 		}
-		if err := commentWriter.WriteComments(comments, includeResolved); err != nil {
+		if err := commentWriter.WriteComments(comments, opts.includeResolved); err != nil {
 			return err
 		}
 
-		tasks, err := client.ListPullRequestTasks(workspace, repo, pr.ID)
-		if err != nil {
-			return fmt.Errorf("failed to fetch tasks: %w", err)
-		}
-
 		if len(tasks) > 0 {
-			fmt.Println()
-			taskWriter := output.NewTaskWriter(os.Stdout)
+			fmt.Fprintln(w)
+			taskWriter := output.NewTaskWriter(w)
 			if err := taskWriter.WriteTasks(tasks); err != nil {
 				return err
 			}
@@ -264,9 +851,107 @@ func resolvePR(client *bitbucket.Client, workspace, repo, ref string) (*bitbucke
 		return client.GetPullRequest(workspace, repo, prID)
 	}
 
+	if looksLikeCommitHash(ref) {
+		pr, err := client.FindPullRequestByCommit(workspace, repo, ref)
+		if err == nil {
+			return pr, nil
+		}
+		if !errors.Is(err, bitbucket.ErrNotFound) {
+			return nil, err
+		}
+		output.LogVerbose("No PR found for commit %s, trying it as a branch name", ref)
+	}
+
 	return client.FindPullRequestByBranch(workspace, repo, ref)
 }
 
+func looksLikeCommitHash(ref string) bool {
+	if !commitHashPattern.MatchString(ref) {
+		return false
+	}
+	// Short all-letter refs like "deadbeef" are more likely branch names.
+	return len(ref) >= 12 || strings.ContainsAny(ref, "0123456789")
+}
+
+func newPRDiffCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "diff <id|branch|commit>",
+		Short: "Print the unified diff of a pull request",
+		Long: `Print the unified diff of a pull request.
+
+The diff is streamed straight from Bitbucket to stdout, so output starts
+immediately and memory stays bounded even for very large pull requests.
+
+Use --file to print only the hunks for one file.`,
+		Args: cobra.ExactArgs(1),
+		RunE: runPRDiff,
+	}
+
+	cmd.Flags().String("repo", "", "Target repository (repo or workspace/repo)")
+	cmd.Flags().String("file", "", "Only show the diff for this file path")
+
+	return cmd
+}
+
+func runPRDiff(cmd *cobra.Command, args []string) error {
+	repoFlag, _ := cmd.Flags().GetString("repo")
+	file, _ := cmd.Flags().GetString("file")
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	workspace, repo, err := resolveRepository(repoFlag, cfg)
+	if err != nil {
+		return err
+	}
+
+	client, err := newClient(cmd, bitbucket.WithWorkspace(workspace))
+	if err != nil {
+		return err
+	}
+
+	pr, err := resolvePR(client, workspace, repo, args[0])
+	if err != nil {
+		return err
+	}
+
+	if file != "" {
+		return printPRFileDiff(client, workspace, repo, pr.ID, file)
+	}
+
+	diff, err := client.GetPullRequestDiffStream(workspace, repo, pr.ID)
+	if err != nil {
+		return err
+	}
+	defer diff.Close()
+
+	if _, err := io.Copy(os.Stdout, diff); err != nil {
+		return fmt.Errorf("failed to write diff: %w", err)
+	}
+	return nil
+}
+
+func printPRFileDiff(client *bitbucket.Client, workspace, repo string, id int, file string) error {
+	diff, err := client.GetPullRequestDiff(workspace, repo, id)
+	if err != nil {
+		return err
+	}
+
+	parser := output.NewDiffParser()
+	if err := parser.Parse(diff); err != nil {
+		return fmt.Errorf("failed to parse diff: %w", err)
+	}
+
+	fileDiff := parser.FileDiff(strings.TrimPrefix(file, "./"))
+	if fileDiff == "" {
+		return fmt.Errorf("PR #%d has no changes to %s", id, file)
+	}
+	_, err = io.WriteString(os.Stdout, fileDiff)
+	return err
+}
+
 func newPRCheckoutCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "checkout <id|branch>",
@@ -275,7 +960,7 @@ func newPRCheckoutCmd() *cobra.Command {
 		RunE:  runPRCheckout,
 	}
 
-	cmd.Flags().String("repo", "", "Target repository")
+	cmd.Flags().String("repo", "", "Target repository (repo or workspace/repo)")
 
 	return cmd
 }
@@ -288,30 +973,12 @@ func runPRCheckout(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 
-	workspace := cfg.Workspace
-	repo := repoFlag
-
-	if repo == "" {
-		inferredWS, inferredRepo, err := git.InferRepository()
-		if err != nil {
-			return fmt.Errorf("could not infer repository: %w\nUse --repo to specify", err)
-		}
-		if workspace == "" {
-			workspace = inferredWS
-		}
-		repo = inferredRepo
-		if verbose {
-			fmt.Fprintf(os.Stderr, "Using repository: %s/%s\n", workspace, repo)
-		}
-	}
-
-	if workspace == "" {
-		return fmt.Errorf("workspace not configured. Run 'atlas config set workspace <name>'")
+	workspace, repo, err := resolveRepository(repoFlag, cfg)
+	if err != nil {
+		return err
 	}
 
-	client, err := bitbucket.NewClient(
-		bitbucket.WithNoCache(noCache),
-	)
+	client, err := newClient(cmd, bitbucket.WithWorkspace(workspace))
 	if err != nil {
 		return err
 	}
@@ -345,3 +1012,353 @@ To checkout manually:
 	fmt.Printf("Switched to branch '%s'\n", branch)
 	return nil
 }
+
+func newPRReviewersCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "reviewers <id|branch>",
+		Short: "Add or remove PR reviewers",
+		Long: `Add or remove reviewers on a pull request. Reviewers are given as usernames
+(or {uuid} values) and can be repeated or comma-separated.
+
+Examples:
+  atlas pr reviewers 123 --add alice --add bob
+  atlas pr reviewers 123 --remove carol`,
+		Args: cobra.ExactArgs(1),
+		RunE: runPRReviewers,
+	}
+
+	cmd.Flags().String("repo", "", "Target repository (repo or workspace/repo)")
+	cmd.Flags().StringSlice("add", nil, "Reviewers to add (usernames, UUIDs, or me)")
+	cmd.Flags().StringSlice("remove", nil, "Reviewers to remove (usernames, UUIDs, or me)")
+
+	return cmd
+}
+
+func runPRReviewers(cmd *cobra.Command, args []string) error {
+	repoFlag, _ := cmd.Flags().GetString("repo")
+	add, _ := cmd.Flags().GetStringSlice("add")
+	remove, _ := cmd.Flags().GetStringSlice("remove")
+
+	if len(add) == 0 && len(remove) == 0 {
+		return fmt.Errorf("at least one of --add or --remove must be specified")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	workspace, repo, err := resolveRepository(repoFlag, cfg)
+	if err != nil {
+		return err
+	}
+
+	client, err := newClient(cmd, bitbucket.WithWorkspace(workspace))
+	if err != nil {
+		return err
+	}
+
+	pr, err := resolvePR(client, workspace, repo, args[0])
+	if err != nil {
+		return err
+	}
+
+	updated, err := client.UpdatePullRequestReviewers(workspace, repo, pr.ID, add, remove)
+	if err != nil {
+		return err
+	}
+
+	var names []string
+	for _, r := range updated.Reviewers {
+		names = append(names, "@"+r.Username)
+	}
+	if len(names) == 0 {
+		fmt.Printf("PR #%d has no reviewers\n", updated.ID)
+		return nil
+	}
+
+	fmt.Printf("PR #%d reviewers: %s\n", updated.ID, strings.Join(names, ", "))
+	return nil
+}
+
+func newPRCommentCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "comment <id|branch>",
+		Short: "Comment on a pull request",
+		Long: `Post a comment on a pull request.
+
+With --file the comment is attached to that file, and with --line as well it
+is anchored to that line of the new version. The anchor is checked against
+the PR diff and a warning is printed if it falls outside it; the comment is
+posted either way.
+
+Examples:
+  atlas pr comment 123 --body "Looks good overall"
+  atlas pr comment 123 --file internal/cli/pr.go --line 42 --body "Handle the error here"`,
+		Args: cobra.ExactArgs(1),
+		RunE: runPRComment,
+	}
+
+	cmd.Flags().String("repo", "", "Target repository (repo or workspace/repo)")
+	cmd.Flags().String("body", "", "Comment text (markdown)")
+	cmd.Flags().String("file", "", "Anchor the comment to this file path")
+	cmd.Flags().Int("line", 0, "Anchor the comment to this line of --file (new side)")
+
+	return cmd
+}
+
+func runPRComment(cmd *cobra.Command, args []string) error {
+	repoFlag, _ := cmd.Flags().GetString("repo")
+	body, _ := cmd.Flags().GetString("body")
+	file, _ := cmd.Flags().GetString("file")
+	line, _ := cmd.Flags().GetInt("line")
+
+	if strings.TrimSpace(body) == "" {
+		return fmt.Errorf("--body is required")
+	}
+	if line < 0 {
+		return fmt.Errorf("--line must be positive")
+	}
+	if line > 0 && file == "" {
+		return fmt.Errorf("--line requires --file")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	workspace, repo, err := resolveRepository(repoFlag, cfg)
+	if err != nil {
+		return err
+	}
+
+	client, err := newClient(cmd, bitbucket.WithWorkspace(workspace))
+	if err != nil {
+		return err
+	}
+
+	pr, err := resolvePR(client, workspace, repo, args[0])
+	if err != nil {
+		return err
+	}
+
+	var inline *bitbucket.Inline
+	if file != "" {
+		inline = &bitbucket.Inline{Path: strings.TrimPrefix(file, "./")}
+		if line > 0 {
+			inline.To = &line
+		}
+		checkCommentAnchor(client, workspace, repo, pr.ID, inline)
+	}
+
+	comment, err := client.CreatePullRequestComment(workspace, repo, pr.ID, body, inline)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Commented on PR #%d: %s\n", pr.ID, comment.Links.HTML.Href)
+	return nil
+}
+
+// checkCommentAnchor warns when an inline anchor does not land in the PR
+// diff. It never blocks the comment: the diff may be too large to fetch, and
+// Bitbucket accepts anchors outside it.
+func checkCommentAnchor(client *bitbucket.Client, workspace, repo string, id int, inline *bitbucket.Inline) {
+	diff, err := client.GetPullRequestDiff(workspace, repo, id)
+	if err != nil {
+		output.LogVerbose("Skipping anchor check: %v", err)
+		return
+	}
+
+	parser := output.NewDiffParser()
+	if err := parser.Parse(diff); err != nil {
+		output.LogVerbose("Skipping anchor check: %v", err)
+		return
+	}
+
+	if parser.FileDiff(inline.Path) == "" {
+		output.LogWarn("%s is not changed in PR #%d", inline.Path, id)
+		return
+	}
+	if inline.To != nil && parser.GetHunkForLine(inline.Path, *inline.To) == nil {
+		output.LogWarn("%s:%d is not part of the PR #%d diff", inline.Path, *inline.To, id)
+	}
+}
+
+func newPRReportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "report",
+		Short: "Summarize pull requests merged in a date range",
+		Long: `Summarize the pull requests merged between two dates: how many, by whom,
+and the average time from creation to merge.
+
+Dates are YYYY-MM-DD (local time, --to inclusive) or RFC 3339 timestamps.
+Bitbucket does not record when a PR was merged, so its last update time is
+used; PRs updated after merging may be counted in a later range.
+
+Examples:
+  atlas pr report --from 2024-03-01 --to 2024-03-14
+  atlas pr report --from 2024-03-01 --json`,
+		Args: cobra.NoArgs,
+		RunE: runPRReport,
+	}
+
+	cmd.Flags().String("repo", "", "Target repository (repo or workspace/repo)")
+	cmd.Flags().String("from", "", "Start of the range (required)")
+	cmd.Flags().String("to", "", "End of the range (default now)")
+	cmd.Flags().Bool("json", false, "Output as JSON")
+
+	return cmd
+}
+
+type PRReportJSON struct {
+	From                time.Time        `json:"from"`
+	To                  time.Time        `json:"to"`
+	Count               int              `json:"count"`
+	AvgTimeToMergeHours float64          `json:"avg_time_to_merge_hours"`
+	Authors             []PRReportAuthor `json:"authors"`
+	Items               []PRListItem     `json:"items"`
+}
+
+type PRReportAuthor struct {
+	Author              string  `json:"author"`
+	AuthorUsername      string  `json:"author_username"`
+	Merged              int     `json:"merged"`
+	AvgTimeToMergeHours float64 `json:"avg_time_to_merge_hours"`
+}
+
+func runPRReport(cmd *cobra.Command, args []string) error {
+	repoFlag, _ := cmd.Flags().GetString("repo")
+	fromFlag, _ := cmd.Flags().GetString("from")
+	toFlag, _ := cmd.Flags().GetString("to")
+
+	if fromFlag == "" {
+		return fmt.Errorf("--from is required")
+	}
+	from, err := parseReportDate(fromFlag, false)
+	if err != nil {
+		return err
+	}
+	to := time.Now()
+	if toFlag != "" {
+		if to, err = parseReportDate(toFlag, true); err != nil {
+			return err
+		}
+	}
+	if !from.Before(to) {
+		return fmt.Errorf("--from must be before --to")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	jsonOutput := useJSONOutput(cmd, cfg)
+
+	workspace, repo, err := resolveRepository(repoFlag, cfg)
+	if err != nil {
+		return err
+	}
+
+	client, err := newClient(cmd, bitbucket.WithWorkspace(workspace))
+	if err != nil {
+		return err
+	}
+
+	prs, err := client.ListMergedPullRequests(workspace, repo, from, to)
+	if err != nil {
+		return err
+	}
+
+	report := newPRReport(prs, from, to)
+	if jsonOutput {
+		return output.WriteJSON(os.Stdout, report)
+	}
+
+	if report.Count == 0 {
+		fmt.Println("No pull requests merged in this range.")
+		return nil
+	}
+
+	fmt.Printf("%d pull requests merged between %s and %s (avg time to merge %s)\n\n",
+		report.Count, from.Format("2006-01-02 15:04"), to.Format("2006-01-02 15:04"), formatHours(report.AvgTimeToMergeHours))
+
+	tw := output.NewTableWriter(os.Stdout, "Author", "Merged", "Avg Time to Merge")
+	for _, a := range report.Authors {
+		tw.AddRow(a.Author, strconv.Itoa(a.Merged), formatHours(a.AvgTimeToMergeHours))
+	}
+	return tw.Flush()
+}
+
+// parseReportDate accepts YYYY-MM-DD in local time or an RFC 3339 timestamp.
+// A bare end date covers the whole day, so it is moved to the next midnight.
+func parseReportDate(value string, end bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q (expected YYYY-MM-DD or RFC 3339)", value)
+	}
+	if end {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+func newPRReport(prs []bitbucket.PullRequest, from, to time.Time) PRReportJSON {
+	type tally struct {
+		author PRReportAuthor
+		total  time.Duration
+	}
+
+	var total time.Duration
+	byAuthor := make(map[string]*tally)
+	for _, pr := range prs {
+		elapsed := pr.UpdatedOn.Sub(pr.CreatedOn)
+		total += elapsed
+
+		key := pr.Author.UUID
+		t, ok := byAuthor[key]
+		if !ok {
+			t = &tally{author: PRReportAuthor{Author: pr.Author.DisplayName, AuthorUsername: pr.Author.Username}}
+			byAuthor[key] = t
+		}
+		t.author.Merged++
+		t.total += elapsed
+	}
+
+	authors := make([]PRReportAuthor, 0, len(byAuthor))
+	for _, t := range byAuthor {
+		t.author.AvgTimeToMergeHours = averageHours(t.total, t.author.Merged)
+		authors = append(authors, t.author)
+	}
+	sort.Slice(authors, func(i, j int) bool {
+		if authors[i].Merged != authors[j].Merged {
+			return authors[i].Merged > authors[j].Merged
+		}
+		return authors[i].Author < authors[j].Author
+	})
+
+	return PRReportJSON{
+		From:                from,
+		To:                  to,
+		Count:               len(prs),
+		AvgTimeToMergeHours: averageHours(total, len(prs)),
+		Authors:             authors,
+		Items:               newPRListJSON(prs).Items,
+	}
+}
+
+func averageHours(total time.Duration, n int) float64 {
+	if n == 0 {
+		return 0
+	}
+	return total.Hours() / float64(n)
+}
+
+func formatHours(hours float64) string {
+	return output.FormatDuration(time.Duration(hours * float64(time.Hour)))
+}
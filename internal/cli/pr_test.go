@@ -0,0 +1,473 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kabilan108/atlas/internal/bitbucket"
+	"github.com/kabilan108/atlas/internal/output"
+)
+
+func TestParseIDRange(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    []int
+		wantErr bool
+	}{
+		{"7", []int{7}, false},
+		{"#7", []int{7}, false},
+		{"1,3,5", []int{1, 3, 5}, false},
+		{"3-6", []int{3, 4, 5, 6}, false},
+		{"#3-#5", []int{3, 4, 5}, false},
+		{"1-3, 2, 8", []int{1, 2, 3, 8}, false},
+		{"5-5", []int{5}, false},
+		{"6-3", nil, true},
+		{"0", nil, true},
+		{"1,,2", nil, true},
+		{"abc", nil, true},
+		{"1-x", nil, true},
+		{fmt.Sprintf("1-%d", maxPRBatchSize+1), nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := parseIDRange(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseIDRange(%q) err = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseIDRange(%q) = %v, want %v", tt.spec, got, tt.want)
+			}
+		})
+	}
+}
+
+// newTestPRClient returns a client for workspace "ws" that talks to handler,
+// with credentials from a throwaway HOME.
+func newTestPRClient(t *testing.T, handler http.Handler, opts ...bitbucket.ClientOption) *bitbucket.Client {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(home, ".cache"))
+	t.Setenv("ATLAS_APP_PASSWORD_FILE", "")
+	t.Chdir(t.TempDir())
+	dir := filepath.Join(home, ".config", "atlas")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	cfg := "workspace = \"ws\"\nusername = \"user\"\napp_password = \"secret\"\n"
+	if err := os.WriteFile(filepath.Join(dir, "config.toml"), []byte(cfg), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	opts = append([]bitbucket.ClientOption{bitbucket.WithBaseURL(srv.URL), bitbucket.WithNoCache(true)}, opts...)
+	client, err := bitbucket.NewClient(opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+// prHandler serves GET /repositories/ws/repo/pullrequests/{id} and counts the
+// fetches of each PR.
+type prHandler struct {
+	mu    sync.Mutex
+	calls map[int]int
+}
+
+func (h *prHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/repositories/ws/repo/pullrequests/"))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	h.mu.Lock()
+	h.calls[id]++
+	h.mu.Unlock()
+	fmt.Fprintf(w, `{"id": %d, "title": "PR %d", "state": "OPEN", "links": {"html": {"href": "https://bitbucket.org/ws/repo/pull-requests/%d"}}}`, id, id, id)
+}
+
+func TestViewPRBatchFetchesEachIDOnce(t *testing.T) {
+	tests := []struct {
+		spec string
+		want map[int]int
+	}{
+		{"1-3", map[int]int{1: 1, 2: 1, 3: 1}},
+		{"1-3,2", map[int]int{1: 1, 2: 1, 3: 1}},
+		{"#4, 4, 4-5, 5", map[int]int{4: 1, 5: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			handler := &prHandler{calls: make(map[int]int)}
+			client := newTestPRClient(t, handler)
+
+			ids, err := parseIDRange(tt.spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			outputDir := t.TempDir()
+			if err := viewPRBatch(io.Discard, client, "ws", "repo", ids, prViewOptions{web: true}, outputDir); err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(handler.calls, tt.want) {
+				t.Errorf("GetPullRequest calls = %v, want %v", handler.calls, tt.want)
+			}
+			files, err := os.ReadDir(outputDir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(files) != len(tt.want) {
+				t.Errorf("wrote %d files, want %d", len(files), len(tt.want))
+			}
+		})
+	}
+}
+
+func TestViewPRBatchCancelled(t *testing.T) {
+	tests := []struct {
+		name string
+		opts prViewOptions
+	}{
+		{"markdown", prViewOptions{}},
+		{"llm", prViewOptions{llm: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// One fetch at a time, so the first PR is rendered before the
+			// second request cancels the batch.
+			saved := prBatchConcurrency
+			prBatchConcurrency = 1
+			t.Cleanup(func() { prBatchConcurrency = saved })
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			var (
+				mu     sync.Mutex
+				served int
+			)
+			prs := &prHandler{calls: make(map[int]int)}
+			client := newTestPRClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				served++
+				first := served == 1
+				mu.Unlock()
+				if first {
+					prs.ServeHTTP(w, r)
+					return
+				}
+				cancel()
+				select {
+				case <-r.Context().Done():
+				case <-time.After(5 * time.Second):
+					http.Error(w, "request was not cancelled", http.StatusGatewayTimeout)
+				}
+			}), bitbucket.WithContext(ctx))
+
+			var out bytes.Buffer
+			err := viewPRBatch(&out, client, "ws", "repo", []int{1, 2, 3}, tt.opts, "")
+			if !errors.Is(err, context.Canceled) {
+				t.Fatalf("viewPRBatch() err = %v, want context.Canceled", err)
+			}
+
+			// Whichever PR was fetched first must be the whole output.
+			if len(prs.calls) != 1 {
+				t.Fatalf("served PRs %v, want exactly one", prs.calls)
+			}
+			var id int
+			for id = range prs.calls {
+				break
+			}
+			pr := &bitbucket.PullRequest{ID: id, Title: fmt.Sprintf("PR %d", id), State: "OPEN"}
+			pr.Links.HTML.Href = fmt.Sprintf("https://bitbucket.org/ws/repo/pull-requests/%d", id)
+			var doc bytes.Buffer
+			if err := writePRView(&doc, nil, "ws", "repo", pr, tt.opts); err != nil {
+				t.Fatal(err)
+			}
+			want := doc.String()
+			if tt.opts.llm {
+				var bundle strings.Builder
+				if err := output.WriteLLMBundle(&bundle, []output.LLMDocument{{
+					Title:   fmt.Sprintf("PR #%d: %s", pr.ID, pr.Title),
+					Source:  pr.Links.HTML.Href,
+					Content: want,
+				}}); err != nil {
+					t.Fatal(err)
+				}
+				want = bundle.String()
+			}
+			if got := out.String(); got != want {
+				t.Errorf("output:\n%s\nwant exactly PR #%d:\n%s", got, id, want)
+			}
+		})
+	}
+}
+
+func TestRunPRViewWithoutArgs(t *testing.T) {
+	const needsTerminal = "interactive selection needs a terminal"
+	tests := []struct {
+		name            string
+		terminal        bool
+		flags           []string
+		wantTerminalErr bool
+	}{
+		{"not a terminal", false, nil, true},
+		{"terminal", true, nil, false},
+		{"terminal with --commit", true, []string{"--commit"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("HOME", t.TempDir())
+			t.Setenv("ATLAS_APP_PASSWORD_FILE", "")
+			t.Chdir(t.TempDir())
+			saved := canPrompt
+			canPrompt = func() bool { return tt.terminal }
+			t.Cleanup(func() { canPrompt = saved })
+
+			cmd := newPRViewCmd()
+			if err := cmd.ParseFlags(tt.flags); err != nil {
+				t.Fatal(err)
+			}
+			err := runPRView(cmd, nil)
+			if err == nil {
+				t.Fatal("runPRView() succeeded, want an error")
+			}
+			// With a terminal the command gets past the check and fails later
+			// on the unconfigured workspace instead.
+			if got := strings.Contains(err.Error(), needsTerminal); got != tt.wantTerminalErr {
+				t.Errorf("runPRView() err = %v, want terminal error %v", err, tt.wantTerminalErr)
+			}
+		})
+	}
+}
+
+func TestParsePRStates(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    []string
+		wantErr bool
+	}{
+		{"open", []string{"OPEN"}, false},
+		{"open,merged", []string{"OPEN", "MERGED"}, false},
+		{" Open , MERGED ,open", []string{"OPEN", "MERGED"}, false},
+		{"declined,superseded", []string{"DECLINED", "SUPERSEDED"}, false},
+		{"open,closed", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := parsePRStates(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parsePRStates(%q) err = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parsePRStates(%q) = %v, want %v", tt.spec, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWritePRViewWeb(t *testing.T) {
+	pr := &bitbucket.PullRequest{ID: 7}
+	pr.Links.HTML.Href = "https://bitbucket.org/ws/repo/pull-requests/7"
+
+	var buf bytes.Buffer
+	// --web needs nothing beyond the PR already fetched, so no client is used.
+	if err := writePRView(&buf, nil, "ws", "repo", pr, prViewOptions{web: true, comments: true, readiness: true}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), "https://bitbucket.org/ws/repo/pull-requests/7\n"; got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
+
+func TestWritePRViewFetchesConcurrently(t *testing.T) {
+	const prefix = "/repositories/ws/repo/pullrequests/7/"
+	bodies := map[string]string{
+		"comments": `{"values": [{"id": 1, "content": {"raw": "looks good"}, "user": {"display_name": "Ann"}}]}`,
+		"diff":     "diff --git a/main.go b/main.go\n",
+		"tasks":    `{"values": [{"id": 2, "content": {"raw": "fix the typo"}, "state": "UNRESOLVED"}]}`,
+	}
+
+	tests := []struct {
+		name      string
+		failing   string
+		wantErr   bool
+		wantLines []string
+	}{
+		{"all succeed", "", false, []string{"looks good", "fix the typo"}},
+		{"diff fails", "diff", false, []string{"looks good", "fix the typo"}},
+		{"comments fail", "comments", true, nil},
+		{"tasks fail", "tasks", true, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				mu      sync.Mutex
+				calls   = make(map[string]int)
+				arrived sync.WaitGroup
+			)
+			// Each fetch is held until all three have arrived, so they only
+			// complete if writePRView issues them together.
+			arrived.Add(len(bodies))
+			released := make(chan struct{})
+			go func() {
+				arrived.Wait()
+				close(released)
+			}()
+
+			client := newTestPRClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				endpoint := strings.TrimPrefix(r.URL.Path, prefix)
+				body, ok := bodies[endpoint]
+				if !ok {
+					http.NotFound(w, r)
+					return
+				}
+				mu.Lock()
+				calls[endpoint]++
+				first := calls[endpoint] == 1
+				mu.Unlock()
+				if first {
+					arrived.Done()
+				}
+
+				select {
+				case <-released:
+				case <-time.After(5 * time.Second):
+					http.Error(w, "fetches did not overlap", http.StatusGatewayTimeout)
+					return
+				}
+				if endpoint == tt.failing {
+					http.Error(w, "boom", http.StatusBadRequest)
+					return
+				}
+				fmt.Fprint(w, body)
+			}))
+
+			pr := &bitbucket.PullRequest{ID: 7, Title: "Fix", State: "OPEN"}
+			var buf bytes.Buffer
+			err := writePRView(&buf, client, "ws", "repo", pr, prViewOptions{comments: true})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("writePRView() err = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			for endpoint := range bodies {
+				if calls[endpoint] != 1 {
+					t.Errorf("%s fetched %d times, want 1", endpoint, calls[endpoint])
+				}
+			}
+			for _, line := range tt.wantLines {
+				if !strings.Contains(buf.String(), line) {
+					t.Errorf("output missing %q:\n%s", line, buf.String())
+				}
+			}
+		})
+	}
+}
+
+func TestPRListJSONEnvelope(t *testing.T) {
+	var pr bitbucket.PullRequest
+	raw := `{
+		"id": 7, "title": "Fix", "state": "OPEN",
+		"author": {"display_name": "Alice", "username": "alice"},
+		"source": {"branch": {"name": "feature/x"}},
+		"destination": {"branch": {"name": "main"}, "repository": {"full_name": "ws/repo"}},
+		"comment_count": 2, "task_count": 1,
+		"created_on": "2024-01-02T03:04:05Z", "updated_on": "2024-01-03T03:04:05Z",
+		"links": {"html": {"href": "https://bitbucket.org/ws/repo/pull-requests/7"}}
+	}`
+	if err := json.Unmarshal([]byte(raw), &pr); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		prs  []bitbucket.PullRequest
+		want string
+	}{
+		{
+			name: "empty list keeps items an array",
+			want: `{"schema_version":1,"items":[]}`,
+		},
+		{
+			name: "stable field names",
+			prs:  []bitbucket.PullRequest{pr},
+			want: `{"schema_version":1,"items":[{"id":7,"title":"Fix","state":"OPEN","author":"Alice","author_username":"alice",` +
+				`"repository":"ws/repo","source_branch":"feature/x","destination_branch":"main","comment_count":2,"task_count":1,` +
+				`"created_on":"2024-01-02T03:04:05Z","updated_on":"2024-01-03T03:04:05Z","url":"https://bitbucket.org/ws/repo/pull-requests/7"}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(newPRListJSON(tt.prs))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got  %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelectPR(t *testing.T) {
+	prs := []bitbucket.PullRequest{
+		{ID: 40, Title: "First", Author: bitbucket.User{DisplayName: "Alice"}},
+		{ID: 42, Title: "Second", Author: bitbucket.User{DisplayName: "Bob"}},
+	}
+
+	tests := []struct {
+		name      string
+		input     string
+		want      int
+		wantErr   bool
+		wantRetry bool
+	}{
+		{name: "valid choice", input: "2\n", want: 42},
+		{name: "reprompts after invalid input", input: "abc\n3\n 1 \n", want: 40, wantRetry: true},
+		{name: "empty answer cancels", input: "\n", wantErr: true},
+		{name: "eof cancels", input: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			got, err := selectPR(prs, strings.NewReader(tt.input), &out)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("selected #%d, want #%d", got, tt.want)
+			}
+			if !strings.Contains(out.String(), "  2. #42 Second (Bob)\n") {
+				t.Errorf("menu missing entry:\n%s", out.String())
+			}
+			if retried := strings.Contains(out.String(), "Enter a number between 1 and 2."); retried != tt.wantRetry {
+				t.Errorf("reprompted = %v, want %v:\n%s", retried, tt.wantRetry, out.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/kabilan108/atlas/internal/bitbucket"
+	"github.com/kabilan108/atlas/internal/config"
+	"github.com/kabilan108/atlas/internal/git"
+	"github.com/kabilan108/atlas/internal/output"
+	"github.com/spf13/cobra"
+)
+
+func newRepoCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "repo",
+		Short: "Work with repositories",
+	}
+
+	cmd.AddCommand(newRepoViewCmd())
+
+	return cmd
+}
+
+func newRepoViewCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "view [workspace/repo|url]",
+		Short: "View repository details",
+		Long: `View a repository's description, main branch, size, language, visibility,
+and clone URLs.
+
+The repository can be given as workspace/repo or any bitbucket.org URL. When
+omitted it is resolved like other commands (--repo, default_repo, git remote).`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: runRepoView,
+	}
+
+	cmd.Flags().String("repo", "", "Target repository (repo or workspace/repo)")
+	cmd.Flags().Bool("json", false, "Output as JSON")
+
+	return cmd
+}
+
+func runRepoView(cmd *cobra.Command, args []string) error {
+	repoFlag, _ := cmd.Flags().GetString("repo")
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	jsonOutput := useJSONOutput(cmd, cfg)
+
+	var workspace, repo string
+	if len(args) == 1 {
+		workspace, repo, err = git.ParseRepoRef(args[0])
+	} else {
+		workspace, repo, err = resolveRepository(repoFlag, cfg)
+	}
+	if err != nil {
+		return err
+	}
+
+	client, err := newClient(cmd, bitbucket.WithWorkspace(workspace))
+	if err != nil {
+		return err
+	}
+
+	repository, err := client.GetRepository(workspace, repo)
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput {
+		return output.WriteJSON(os.Stdout, repository)
+	}
+
+	visibility := "public"
+	if repository.IsPrivate {
+		visibility = "private"
+	}
+	mainBranch := "-"
+	if repository.MainBranch != nil {
+		mainBranch = repository.MainBranch.Name
+	}
+	language := repository.Language
+	if language == "" {
+		language = "-"
+	}
+
+	fmt.Printf("Name:        %s\n", repository.FullName)
+	if repository.Description != "" {
+		fmt.Printf("Description: %s\n", repository.Description)
+	}
+	fmt.Printf("Main branch: %s\n", mainBranch)
+	fmt.Printf("Language:    %s\n", language)
+	fmt.Printf("Size:        %s\n", output.FormatSize(repository.Size))
+	fmt.Printf("Visibility:  %s\n", visibility)
+	fmt.Printf("Updated:     %s\n", output.FormatRelativeTime(repository.UpdatedOn))
+	fmt.Printf("URL:         %s\n", repository.Links.HTML.Href)
+
+	if len(repository.Links.Clone) > 0 {
+		fmt.Println()
+		fmt.Println("Clone:")
+		for _, link := range repository.Links.Clone {
+			fmt.Printf("  %-6s %s\n", link.Name, link.Href)
+		}
+	}
+
+	return nil
+}
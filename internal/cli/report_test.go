@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kabilan108/atlas/internal/bitbucket"
+)
+
+func TestParseReportDate(t *testing.T) {
+	tests := []struct {
+		value   string
+		end     bool
+		want    time.Time
+		wantErr bool
+	}{
+		{value: "2024-03-01", want: time.Date(2024, 3, 1, 0, 0, 0, 0, time.Local)},
+		{value: "2024-03-14", end: true, want: time.Date(2024, 3, 15, 0, 0, 0, 0, time.Local)},
+		{value: "2024-03-14T10:00:00Z", end: true, want: time.Date(2024, 3, 14, 10, 0, 0, 0, time.UTC)},
+		{value: "03/14/2024", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseReportDate(tt.value, tt.end)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("parseReportDate(%q, %v) = %v, want %v", tt.value, tt.end, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewPRReport(t *testing.T) {
+	created := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	merged := func(id int, uuid, name string, hours int) bitbucket.PullRequest {
+		return bitbucket.PullRequest{
+			ID:        id,
+			State:     "MERGED",
+			Author:    bitbucket.User{UUID: uuid, DisplayName: name, Username: name},
+			CreatedOn: created,
+			UpdatedOn: created.Add(time.Duration(hours) * time.Hour),
+		}
+	}
+
+	tests := []struct {
+		name        string
+		prs         []bitbucket.PullRequest
+		wantAvg     float64
+		wantAuthors []PRReportAuthor
+	}{
+		{
+			name:        "empty range",
+			wantAuthors: []PRReportAuthor{},
+		},
+		{
+			name: "authors ranked by merged count then name",
+			prs: []bitbucket.PullRequest{
+				merged(1, "{b}", "bob", 2),
+				merged(2, "{a}", "alice", 4),
+				merged(3, "{c}", "carol", 6),
+				merged(4, "{c}", "carol", 12),
+			},
+			wantAvg: 6,
+			wantAuthors: []PRReportAuthor{
+				{Author: "carol", AuthorUsername: "carol", Merged: 2, AvgTimeToMergeHours: 9},
+				{Author: "alice", AuthorUsername: "alice", Merged: 1, AvgTimeToMergeHours: 4},
+				{Author: "bob", AuthorUsername: "bob", Merged: 1, AvgTimeToMergeHours: 2},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := newPRReport(tt.prs, created, created.Add(24*time.Hour))
+			if report.Count != len(tt.prs) || len(report.Items) != len(tt.prs) {
+				t.Errorf("count = %d, items = %d, want %d", report.Count, len(report.Items), len(tt.prs))
+			}
+			if report.AvgTimeToMergeHours != tt.wantAvg {
+				t.Errorf("average = %v, want %v", report.AvgTimeToMergeHours, tt.wantAvg)
+			}
+			if len(report.Authors) != len(tt.wantAuthors) {
+				t.Fatalf("authors = %+v, want %+v", report.Authors, tt.wantAuthors)
+			}
+			for i, a := range report.Authors {
+				if a != tt.wantAuthors[i] {
+					t.Errorf("author %d = %+v, want %+v", i, a, tt.wantAuthors[i])
+				}
+			}
+		})
+	}
+}
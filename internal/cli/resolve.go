@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/kabilan108/atlas/internal/config"
+	"github.com/kabilan108/atlas/internal/git"
+	"github.com/kabilan108/atlas/internal/output"
+)
+
+var errWorkspaceNotConfigured = errors.New("workspace not configured. Run 'atlas config set workspace <name>'")
+
+// resolveWorkspace applies the workspace precedence shared by all commands:
+// the --workspace flag, then the git remote (when allowInfer is set), then
+// the configured workspace.
+func resolveWorkspace(flag string, cfg *config.Config, allowInfer bool) (string, error) {
+	if flag != "" {
+		return flag, nil
+	}
+	if allowInfer {
+		if workspace, _, err := git.InferRepository(); err == nil {
+			return workspace, nil
+		}
+	}
+	if cfg.Workspace != "" {
+		return cfg.Workspace, nil
+	}
+	return "", errWorkspaceNotConfigured
+}
+
+// resolveRepository picks the repository from --repo, then default_repo, then
+// the git remote. A "workspace/repo" value and an inferred repository bring
+// their own workspace, overriding the configured one.
+func resolveRepository(repoFlag string, cfg *config.Config) (string, string, error) {
+	repo := repoFlag
+	if repo == "" {
+		repo = cfg.DefaultRepo
+	}
+
+	if strings.Contains(repo, "/") {
+		return git.ParseRepoRef(repo)
+	}
+
+	if repo != "" {
+		workspace, err := resolveWorkspace("", cfg, false)
+		if err != nil {
+			return "", "", err
+		}
+		return workspace, repo, nil
+	}
+
+	workspace, repo, err := git.InferRepository()
+	if err != nil {
+		return "", "", fmt.Errorf("could not infer repository: %w\nUse --repo to specify", err)
+	}
+	output.LogVerbose("Using repository: %s/%s", workspace, repo)
+	return workspace, repo, nil
+}
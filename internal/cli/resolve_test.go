@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kabilan108/atlas/internal/config"
+)
+
+// chdirRepo moves into a fresh directory whose .git/config points origin at
+// remote. An empty remote leaves the directory outside any repository.
+func chdirRepo(t *testing.T, remote string) {
+	t.Helper()
+	dir := t.TempDir()
+	if remote != "" {
+		gitDir := filepath.Join(dir, ".git")
+		if err := os.MkdirAll(gitDir, 0700); err != nil {
+			t.Fatal(err)
+		}
+		content := "[remote \"origin\"]\n\turl = " + remote + "\n"
+		if err := os.WriteFile(filepath.Join(gitDir, "config"), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Chdir(dir)
+}
+
+func TestResolveWorkspace(t *testing.T) {
+	tests := []struct {
+		name       string
+		flag       string
+		configured string
+		remote     string
+		allowInfer bool
+		want       string
+		wantErr    error
+	}{
+		{"flag wins", "flag-ws", "cfg-ws", "git@bitbucket.org:git-ws/repo.git", true, "flag-ws", nil},
+		{"remote before config", "", "cfg-ws", "git@bitbucket.org:git-ws/repo.git", true, "git-ws", nil},
+		{"remote ignored without infer", "", "cfg-ws", "git@bitbucket.org:git-ws/repo.git", false, "cfg-ws", nil},
+		{"config outside a repo", "", "cfg-ws", "", true, "cfg-ws", nil},
+		{"foreign remote falls back", "", "cfg-ws", "git@github.com:me/repo.git", true, "cfg-ws", nil},
+		{"nothing configured", "", "", "", true, "", errWorkspaceNotConfigured},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chdirRepo(t, tt.remote)
+
+			got, err := resolveWorkspace(tt.flag, &config.Config{Workspace: tt.configured}, tt.allowInfer)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("workspace = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveRepository(t *testing.T) {
+	tests := []struct {
+		name          string
+		flag          string
+		cfg           config.Config
+		remote        string
+		wantWorkspace string
+		wantRepo      string
+		wantErr       bool
+	}{
+		{"flag with configured workspace", "api", config.Config{Workspace: "cfg-ws"}, "", "cfg-ws", "api", false},
+		{"slug flag brings its workspace", "other/api", config.Config{Workspace: "cfg-ws"}, "", "other", "api", false},
+		{"url flag brings its workspace", "https://bitbucket.org/other/api/src/main", config.Config{Workspace: "cfg-ws"}, "", "other", "api", false},
+		{"slug default repo brings its workspace", "", config.Config{Workspace: "cfg-ws", DefaultRepo: "other/web"}, "", "other", "web", false},
+		{"malformed slug flag", "a/b/c", config.Config{Workspace: "cfg-ws"}, "", "", "", true},
+		{"default repo", "", config.Config{Workspace: "cfg-ws", DefaultRepo: "web"}, "", "cfg-ws", "web", false},
+		{"inferred repo keeps remote workspace", "", config.Config{Workspace: "cfg-ws"}, "https://bitbucket.org/git-ws/tool.git", "git-ws", "tool", false},
+		{"flag without workspace", "api", config.Config{}, "", "", "", true},
+		{"nothing to infer", "", config.Config{Workspace: "cfg-ws"}, "", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chdirRepo(t, tt.remote)
+
+			workspace, repo, err := resolveRepository(tt.flag, &tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if workspace != tt.wantWorkspace || repo != tt.wantRepo {
+				t.Errorf("resolveRepository = %s/%s, want %s/%s", workspace, repo, tt.wantWorkspace, tt.wantRepo)
+			}
+		})
+	}
+}
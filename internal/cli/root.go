@@ -1,32 +1,158 @@
 package cli
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/kabilan108/atlas/internal/bitbucket"
+	"github.com/kabilan108/atlas/internal/config"
+	"github.com/kabilan108/atlas/internal/output"
 	"github.com/spf13/cobra"
 )
 
 var (
-	noCache bool
-	verbose bool
+	noCache    bool
+	offline    bool
+	noPager    bool
+	retry      bool
+	verbose    bool
+	quiet      bool
+	logFormat  string
+	deadline   time.Duration
+	dryRun     bool
+	ascii      bool
+	tableStyle string
+	maxPages   int
+	allPages   bool
+
+	cancelDeadline context.CancelFunc
 )
 
-func NewRootCmd(version string) *cobra.Command {
+const defaultMaxPages = 20
+
+func NewRootCmd(info BuildInfo) *cobra.Command {
 	rootCmd := &cobra.Command{
 		Use:     "atlas",
 		Short:   "CLI tool for interacting with Bitbucket Cloud",
 		Long:    "Atlas enables fetching PR comments and review feedback from Bitbucket Cloud\nin a format optimized for Claude Code agents to address reviewer comments directly.",
-		Version: version,
+		Version: info.String(),
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			output.DefaultLogger().SetVerbose(verbose)
+			output.DefaultLogger().SetQuiet(quiet)
+			output.SetASCII(ascii)
+			if err := output.SetTableStyle(tableStyle); err != nil {
+				return err
+			}
+			if offline && noCache {
+				return fmt.Errorf("--offline and --no-cache cannot be used together")
+			}
+			if deadline > 0 {
+				ctx, cancel := context.WithTimeout(cmd.Context(), deadline)
+				cmd.SetContext(ctx)
+				cancelDeadline = cancel
+			}
+			return output.DefaultLogger().SetFormat(logFormat)
+		},
+		SilenceErrors: true,
 	}
 
 	rootCmd.PersistentFlags().BoolVar(&noCache, "no-cache", false, "Bypass disk cache entirely")
+	rootCmd.PersistentFlags().BoolVar(&offline, "offline", false, "Serve reads only from the disk cache and never touch the network")
+	rootCmd.PersistentFlags().BoolVar(&noPager, "no-pager", false, "Never page long output through $PAGER")
+	rootCmd.PersistentFlags().BoolVar(&retry, "retry", false, "Wait and retry on rate limits and server errors (idempotent requests only)")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Show inferred values (repo from git remote, etc.)")
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "Suppress informational stderr output (errors are still shown)")
+	rootCmd.PersistentFlags().DurationVar(&deadline, "deadline", 0, "Abort the whole command after this duration (e.g. 30s, 2m)")
+	rootCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "Print write requests to stderr instead of sending them")
+	rootCmd.PersistentFlags().BoolVar(&ascii, "ascii", false, "Use ASCII instead of Unicode glyphs in output (for non-UTF-8 terminals)")
+	rootCmd.PersistentFlags().IntVar(&maxPages, "max-pages", defaultMaxPages, "Stop paginated listings after this many pages")
+	rootCmd.PersistentFlags().BoolVar(&allPages, "all-pages", false, "Fetch every page of paginated listings (removes the --max-pages cap)")
+	rootCmd.PersistentFlags().StringVar(&tableStyle, "table-style", output.TableStyleAligned, "Style for list tables: aligned, markdown (pipe table)")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", output.LogFormatText, "Format for stderr log messages: text, json")
 
+	rootCmd.AddCommand(newCommitCmd())
 	rootCmd.AddCommand(newConfigCmd())
+	rootCmd.AddCommand(newDoctorCmd())
 	rootCmd.AddCommand(newPRCmd())
+	rootCmd.AddCommand(newRepoCmd())
 	rootCmd.AddCommand(newSnippetCmd())
+	rootCmd.AddCommand(newVersionCmd(info))
+	rootCmd.AddCommand(newWorkspaceCmd())
 
 	return rootCmd
 }
 
-func Execute(version string) error {
-	return NewRootCmd(version).Execute()
+// Execute runs the CLI with a context that is cancelled on SIGINT/SIGTERM, so
+// in-flight requests stop and batch commands can flush what already completed.
+func Execute(info BuildInfo) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	err := NewRootCmd(info).ExecuteContext(ctx)
+	if cancelDeadline != nil {
+		cancelDeadline()
+	}
+	if errors.Is(err, bitbucket.ErrDryRun) {
+		return nil
+	}
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			err = fmt.Errorf("deadline of %s exceeded: %w", deadline, err)
+		} else if errors.Is(err, context.Canceled) {
+			err = fmt.Errorf("interrupted: %w", err)
+		}
+		output.LogError("%s", err)
+		return err
+	}
+	return nil
+}
+
+func newClient(cmd *cobra.Command, opts ...bitbucket.ClientOption) (*bitbucket.Client, error) {
+	base := []bitbucket.ClientOption{
+		bitbucket.WithNoCache(noCache),
+		bitbucket.WithOffline(offline),
+		bitbucket.WithRetry(retry),
+		bitbucket.WithContext(cmd.Context()),
+		bitbucket.WithRetryStats(logRetryStats),
+		bitbucket.WithPageCapWarning(logPageCap),
+	}
+	if !allPages {
+		base = append(base, bitbucket.WithMaxPages(maxPages))
+	}
+	if dryRun {
+		base = append(base, bitbucket.WithDryRun(os.Stderr))
+	}
+	return bitbucket.NewClient(append(base, opts...)...)
+}
+
+func logRetryStats(stats bitbucket.RetryStats) {
+	reason := "server error"
+	if stats.RateLimited {
+		reason = "rate limited"
+	}
+	output.LogVerbose("%s %s: %d attempts, waited %s (%s)",
+		stats.Method, stats.URL, stats.Attempts, stats.Backoff.Round(time.Millisecond), reason)
+}
+
+func logPageCap(endpoint string, maxPages int) {
+	output.LogWarn("%s: stopped after %d pages; results are incomplete (use --max-pages or --all-pages)", endpoint, maxPages)
+}
+
+func useJSONOutput(cmd *cobra.Command, cfg *config.Config) bool {
+	if cmd.Flags().Changed("json") {
+		jsonOutput, _ := cmd.Flags().GetBool("json")
+		return jsonOutput
+	}
+	switch cfg.DefaultFormat {
+	case config.FormatJSON:
+		return true
+	case config.FormatAuto:
+		return !output.IsTerminal(os.Stdout)
+	}
+	return false
 }
@@ -0,0 +1,39 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/kabilan108/atlas/internal/config"
+	"github.com/spf13/cobra"
+)
+
+func TestUseJSONOutput(t *testing.T) {
+	tests := []struct {
+		name   string
+		args   []string
+		format string
+		want   bool
+	}{
+		{"default", nil, "", false},
+		{"config json", nil, config.FormatJSON, true},
+		{"config markdown", nil, config.FormatMarkdown, false},
+		{"flag wins over config", []string{"--json=false"}, config.FormatJSON, false},
+		{"flag set", []string{"--json"}, config.FormatMarkdown, true},
+		// Test output is never a terminal, so auto picks JSON.
+		{"auto when piped", nil, config.FormatAuto, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := &cobra.Command{Use: "test"}
+			cmd.Flags().Bool("json", false, "")
+			if err := cmd.ParseFlags(tt.args); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := useJSONOutput(cmd, &config.Config{DefaultFormat: tt.format}); got != tt.want {
+				t.Errorf("useJSONOutput = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
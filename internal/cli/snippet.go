@@ -2,8 +2,11 @@ package cli
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"sort"
+	"sync"
 
 	"github.com/kabilan108/atlas/internal/bitbucket"
 	"github.com/kabilan108/atlas/internal/config"
@@ -22,6 +25,7 @@ func newSnippetCmd() *cobra.Command {
 	cmd.AddCommand(newSnippetCreateCmd())
 	cmd.AddCommand(newSnippetUpdateCmd())
 	cmd.AddCommand(newSnippetDeleteCmd())
+	cmd.AddCommand(newSnippetHistoryCmd())
 
 	return cmd
 }
@@ -34,6 +38,7 @@ func newSnippetListCmd() *cobra.Command {
 	}
 
 	cmd.Flags().String("workspace", "", "Target workspace")
+	cmd.Flags().Bool("all", false, "List snippets across all accessible workspaces")
 	cmd.Flags().Bool("json", false, "Output as JSON")
 
 	return cmd
@@ -41,27 +46,34 @@ func newSnippetListCmd() *cobra.Command {
 
 func runSnippetList(cmd *cobra.Command, args []string) error {
 	workspaceFlag, _ := cmd.Flags().GetString("workspace")
-	jsonOutput, _ := cmd.Flags().GetBool("json")
+	allWorkspaces, _ := cmd.Flags().GetBool("all")
 
 	cfg, err := config.Load()
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 
-	workspace := workspaceFlag
-	if workspace == "" {
-		workspace = cfg.Workspace
-	}
-	if workspace == "" {
-		return fmt.Errorf("workspace not configured. Run 'atlas config set workspace <name>' or use --workspace")
+	jsonOutput := useJSONOutput(cmd, cfg)
+
+	var workspace string
+	if !allWorkspaces {
+		workspace, err = resolveWorkspace(workspaceFlag, cfg, false)
+		if err != nil {
+			return err
+		}
 	}
 
-	client, err := bitbucket.NewClient(bitbucket.WithNoCache(noCache))
+	client, err := newClient(cmd, bitbucket.WithWorkspace(workspace))
 	if err != nil {
 		return err
 	}
 
-	snippets, err := client.ListSnippets(workspace)
+	var snippets []bitbucket.Snippet
+	if allWorkspaces {
+		snippets, err = client.ListAllSnippets()
+	} else {
+		snippets, err = client.ListSnippets(workspace)
+	}
 	if err != nil {
 		return err
 	}
@@ -75,19 +87,32 @@ func runSnippetList(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
-	tw := output.NewTableWriter(os.Stdout, "ID", "Title", "Files", "Visibility", "Updated")
+	headers := []string{"ID", "Title", "Files", "Visibility", "Updated"}
+	if allWorkspaces {
+		headers = append(headers, "Workspace")
+	}
+
+	tw := output.NewTableWriter(os.Stdout, headers...)
 	for _, s := range snippets {
 		visibility := "public"
 		if s.IsPrivate {
 			visibility = "private"
 		}
-		tw.AddRow(
+		row := []string{
 			s.ID,
 			output.Truncate(s.Title, 40),
 			fmt.Sprintf("%d", len(s.Files)),
 			visibility,
 			output.FormatRelativeTime(s.UpdatedOn),
-		)
+		}
+		if allWorkspaces {
+			owner := s.Workspace.Slug
+			if owner == "" {
+				owner = s.Owner.Username
+			}
+			row = append(row, owner)
+		}
+		tw.AddRow(row...)
 	}
 
 	return tw.Flush()
@@ -102,12 +127,69 @@ func newSnippetViewCmd() *cobra.Command {
 	}
 
 	cmd.Flags().String("workspace", "", "Target workspace")
-	cmd.Flags().Bool("contents", false, "Display file contents")
+	cmd.Flags().Bool("contents", false, "Display file contents as fenced markdown")
+	cmd.Flags().Bool("raw", false, "With --contents, print files verbatim instead of as markdown")
+	cmd.Flags().String("revision", "", "Show the snippet as of this commit hash (see 'snippet history')")
+	cmd.Flags().Int("concurrency", 4, "Number of files to fetch in parallel (with --contents)")
 	cmd.Flags().Bool("json", false, "Output as JSON")
 
 	return cmd
 }
 
+type snippetFileContent struct {
+	name    string
+	content []byte
+	err     error
+}
+
+// fetchSnippetFiles downloads every file of a snippet in parallel and returns
+// them sorted by filename. A failed file carries its error so the others can
+// still be shown.
+func fetchSnippetFiles(client *bitbucket.Client, workspace, snippetID, revision string, snippet *bitbucket.Snippet, concurrency int) []snippetFileContent {
+	files := make([]snippetFileContent, 0, len(snippet.Files))
+	for filename := range snippet.Files {
+		files = append(files, snippetFileContent{name: filename})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].name < files[j].name })
+
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i := range files {
+		wg.Add(1)
+		go func(f *snippetFileContent) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			f.content, f.err = client.GetSnippetFileContentAt(workspace, snippetID, revision, f.name)
+			if f.err != nil {
+				f.err = fmt.Errorf("failed to fetch file %s: %w", f.name, f.err)
+			}
+		}(&files[i])
+	}
+	wg.Wait()
+
+	return files
+}
+
+func snippetFileErrors(files []snippetFileContent) error {
+	var errs []error
+	for _, f := range files {
+		if f.err != nil {
+			output.LogError("%v", f.err)
+			errs = append(errs, f.err)
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return &bitbucket.PartialError{Errors: errs}
+}
+
 type SnippetViewJSON struct {
 	*bitbucket.Snippet
 	FileContents map[string]string `json:"file_contents,omitempty"`
@@ -117,44 +199,48 @@ func runSnippetView(cmd *cobra.Command, args []string) error {
 	snippetID := args[0]
 	workspaceFlag, _ := cmd.Flags().GetString("workspace")
 	showContents, _ := cmd.Flags().GetBool("contents")
-	jsonOutput, _ := cmd.Flags().GetBool("json")
+	raw, _ := cmd.Flags().GetBool("raw")
+	concurrency, _ := cmd.Flags().GetInt("concurrency")
+	revision, _ := cmd.Flags().GetString("revision")
 
 	cfg, err := config.Load()
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 
-	workspace := workspaceFlag
-	if workspace == "" {
-		workspace = cfg.Workspace
-	}
-	if workspace == "" {
-		return fmt.Errorf("workspace not configured. Run 'atlas config set workspace <name>' or use --workspace")
+	jsonOutput := useJSONOutput(cmd, cfg)
+
+	workspace, err := resolveWorkspace(workspaceFlag, cfg, false)
+	if err != nil {
+		return err
 	}
 
-	client, err := bitbucket.NewClient(bitbucket.WithNoCache(noCache))
+	client, err := newClient(cmd, bitbucket.WithWorkspace(workspace))
 	if err != nil {
 		return err
 	}
 
-	snippet, err := client.GetSnippet(workspace, snippetID)
+	snippet, err := client.GetSnippetAt(workspace, snippetID, revision)
 	if err != nil {
 		return err
 	}
 
 	if jsonOutput {
 		result := SnippetViewJSON{Snippet: snippet}
+		var files []snippetFileContent
 		if showContents {
+			files = fetchSnippetFiles(client, workspace, snippetID, revision, snippet, concurrency)
 			result.FileContents = make(map[string]string)
-			for filename := range snippet.Files {
-				content, err := client.GetSnippetFileContent(workspace, snippetID, filename)
-				if err != nil {
-					return fmt.Errorf("failed to fetch file %s: %w", filename, err)
+			for _, f := range files {
+				if f.err == nil {
+					result.FileContents[f.name] = string(f.content)
 				}
-				result.FileContents[filename] = string(content)
 			}
 		}
-		return output.WriteJSON(os.Stdout, result)
+		if err := output.WriteJSON(os.Stdout, result); err != nil {
+			return err
+		}
+		return snippetFileErrors(files)
 	}
 
 	visibility := "public"
@@ -162,49 +248,71 @@ func runSnippetView(cmd *cobra.Command, args []string) error {
 		visibility = "private"
 	}
 
-	fmt.Printf("Title:      %s\n", snippet.Title)
+	fmt.Printf("Title:      %s\n", output.SanitizeLine(snippet.Title))
 	fmt.Printf("ID:         %s\n", snippet.ID)
-	fmt.Printf("Owner:      %s\n", snippet.Owner.DisplayName)
+	fmt.Printf("Owner:      %s\n", output.SanitizeLine(snippet.Owner.DisplayName))
 	fmt.Printf("Visibility: %s\n", visibility)
 	fmt.Printf("Created:    %s\n", output.FormatRelativeTime(snippet.CreatedOn))
 	fmt.Printf("Updated:    %s\n", output.FormatRelativeTime(snippet.UpdatedOn))
 	fmt.Printf("URL:        %s\n", snippet.Links.HTML.Href)
+	if revision != "" {
+		fmt.Printf("Revision:   %s\n", revision)
+	}
 	fmt.Println()
 
-	fmt.Printf("Files (%d):\n", len(snippet.Files))
+	filenames := make([]string, 0, len(snippet.Files))
 	for filename := range snippet.Files {
-		fmt.Printf("  - %s\n", filename)
+		filenames = append(filenames, filename)
 	}
+	sort.Strings(filenames)
 
-	if showContents {
-		fmt.Println()
-		for filename := range snippet.Files {
-			content, err := client.GetSnippetFileContent(workspace, snippetID, filename)
-			if err != nil {
-				return fmt.Errorf("failed to fetch file %s: %w", filename, err)
-			}
-			fmt.Printf("=== %s ===\n", filename)
-			fmt.Println(string(content))
+	fmt.Printf("Files (%d):\n", len(filenames))
+	for _, filename := range filenames {
+		fmt.Printf("  - %s\n", output.SanitizeLine(filename))
+	}
+
+	if !showContents {
+		return nil
+	}
+
+	fmt.Println()
+	files := fetchSnippetFiles(client, workspace, snippetID, revision, snippet, concurrency)
+	for _, f := range files {
+		if f.err != nil {
+			continue
+		}
+		if raw {
+			fmt.Printf("=== %s ===\n", f.name)
+			fmt.Println(string(f.content))
+			continue
+		}
+		if err := output.WriteCodeBlock(os.Stdout, f.name, string(f.content)); err != nil {
+			return err
 		}
 	}
 
-	return nil
+	return snippetFileErrors(files)
 }
 
 func newSnippetCreateCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "create",
 		Short: "Create a new snippet",
-		RunE:  runSnippetCreate,
+		Long: `Create a new snippet from one or more files.
+
+Without --file, the snippet is created from stdin. The file is named with
+--filename, or else after the slugified title plus an extension guessed from
+the content (e.g. "cmd | atlas snippet create --title logs" creates logs.txt).`,
+		RunE: runSnippetCreate,
 	}
 
 	cmd.Flags().String("workspace", "", "Target workspace")
 	cmd.Flags().String("title", "", "Snippet title")
-	cmd.Flags().StringSliceP("file", "f", nil, "Files to include")
+	cmd.Flags().StringSliceP("file", "f", nil, "Files to include (default: read stdin)")
+	cmd.Flags().String("filename", "", "Filename for content read from stdin")
 	cmd.Flags().Bool("private", true, "Make snippet private (visible to workspace members only)")
 	cmd.Flags().Bool("json", false, "Output as JSON")
 	cmd.MarkFlagRequired("title")
-	cmd.MarkFlagRequired("file")
 
 	return cmd
 }
@@ -213,20 +321,19 @@ func runSnippetCreate(cmd *cobra.Command, args []string) error {
 	workspaceFlag, _ := cmd.Flags().GetString("workspace")
 	title, _ := cmd.Flags().GetString("title")
 	files, _ := cmd.Flags().GetStringSlice("file")
+	stdinName, _ := cmd.Flags().GetString("filename")
 	isPrivate, _ := cmd.Flags().GetBool("private")
-	jsonOutput, _ := cmd.Flags().GetBool("json")
 
 	cfg, err := config.Load()
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 
-	workspace := workspaceFlag
-	if workspace == "" {
-		workspace = cfg.Workspace
-	}
-	if workspace == "" {
-		return fmt.Errorf("workspace not configured. Run 'atlas config set workspace <name>' or use --workspace")
+	jsonOutput := useJSONOutput(cmd, cfg)
+
+	workspace, err := resolveWorkspace(workspaceFlag, cfg, false)
+	if err != nil {
+		return err
 	}
 
 	fileContents := make(map[string][]byte)
@@ -239,7 +346,15 @@ func runSnippetCreate(cmd *cobra.Command, args []string) error {
 		fileContents[filename] = content
 	}
 
-	client, err := bitbucket.NewClient(bitbucket.WithNoCache(noCache))
+	if len(files) == 0 {
+		filename, content, err := readSnippetStdin(title, stdinName)
+		if err != nil {
+			return err
+		}
+		fileContents[filename] = content
+	}
+
+	client, err := newClient(cmd, bitbucket.WithWorkspace(workspace))
 	if err != nil {
 		return err
 	}
@@ -259,6 +374,25 @@ func runSnippetCreate(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+func readSnippetStdin(title, filename string) (string, []byte, error) {
+	if output.IsTerminal(os.Stdin) {
+		return "", nil, fmt.Errorf("no files given: pass --file or pipe content on stdin")
+	}
+
+	content, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read from stdin: %w", err)
+	}
+	if len(content) == 0 {
+		return "", nil, fmt.Errorf("no content on stdin")
+	}
+
+	if filename == "" {
+		filename = output.Slugify(title) + output.DetectExtension(content)
+	}
+	return filename, content, nil
+}
+
 func newSnippetUpdateCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "update <id>",
@@ -270,6 +404,9 @@ func newSnippetUpdateCmd() *cobra.Command {
 	cmd.Flags().String("workspace", "", "Target workspace")
 	cmd.Flags().StringSliceP("file", "f", nil, "Files to add or update")
 	cmd.Flags().StringSliceP("remove", "r", nil, "Files to remove")
+	cmd.Flags().Bool("private", false, "Make snippet private")
+	cmd.Flags().Bool("public", false, "Make snippet public")
+	cmd.MarkFlagsMutuallyExclusive("private", "public")
 
 	return cmd
 }
@@ -279,9 +416,16 @@ func runSnippetUpdate(cmd *cobra.Command, args []string) error {
 	workspaceFlag, _ := cmd.Flags().GetString("workspace")
 	files, _ := cmd.Flags().GetStringSlice("file")
 	removeFiles, _ := cmd.Flags().GetStringSlice("remove")
+	makePrivate, _ := cmd.Flags().GetBool("private")
+	makePublic, _ := cmd.Flags().GetBool("public")
+
+	var isPrivate *bool
+	if makePrivate || makePublic {
+		isPrivate = &makePrivate
+	}
 
-	if len(files) == 0 && len(removeFiles) == 0 {
-		return fmt.Errorf("at least one of --file or --remove must be specified")
+	if len(files) == 0 && len(removeFiles) == 0 && isPrivate == nil {
+		return fmt.Errorf("at least one of --file, --remove, --private, or --public must be specified")
 	}
 
 	cfg, err := config.Load()
@@ -289,12 +433,9 @@ func runSnippetUpdate(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 
-	workspace := workspaceFlag
-	if workspace == "" {
-		workspace = cfg.Workspace
-	}
-	if workspace == "" {
-		return fmt.Errorf("workspace not configured. Run 'atlas config set workspace <name>' or use --workspace")
+	workspace, err := resolveWorkspace(workspaceFlag, cfg, false)
+	if err != nil {
+		return err
 	}
 
 	fileContents := make(map[string][]byte)
@@ -307,16 +448,23 @@ func runSnippetUpdate(cmd *cobra.Command, args []string) error {
 		fileContents[filename] = content
 	}
 
-	client, err := bitbucket.NewClient(bitbucket.WithNoCache(noCache))
+	client, err := newClient(cmd, bitbucket.WithWorkspace(workspace))
 	if err != nil {
 		return err
 	}
 
-	if err := client.UpdateSnippet(workspace, snippetID, fileContents, removeFiles); err != nil {
+	if err := client.UpdateSnippet(workspace, snippetID, fileContents, removeFiles, isPrivate); err != nil {
 		return err
 	}
 
 	fmt.Printf("Updated snippet: %s\n", snippetID)
+	if isPrivate != nil {
+		visibility := "public"
+		if *isPrivate {
+			visibility = "private"
+		}
+		fmt.Printf("Visibility: %s\n", visibility)
+	}
 
 	return nil
 }
@@ -338,12 +486,12 @@ func runSnippetDelete(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 
-	workspace := cfg.Workspace
-	if workspace == "" {
-		return fmt.Errorf("workspace not configured. Run 'atlas config set workspace <name>'")
+	workspace, err := resolveWorkspace("", cfg, false)
+	if err != nil {
+		return err
 	}
 
-	client, err := bitbucket.NewClient(bitbucket.WithNoCache(noCache))
+	client, err := newClient(cmd, bitbucket.WithWorkspace(workspace))
 	if err != nil {
 		return err
 	}
@@ -356,3 +504,65 @@ func runSnippetDelete(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+func newSnippetHistoryCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "history <id>",
+		Short: "List a snippet's revisions",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runSnippetHistory,
+	}
+
+	cmd.Flags().String("workspace", "", "Target workspace")
+	cmd.Flags().Bool("json", false, "Output as JSON")
+
+	return cmd
+}
+
+func runSnippetHistory(cmd *cobra.Command, args []string) error {
+	snippetID := args[0]
+	workspaceFlag, _ := cmd.Flags().GetString("workspace")
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	jsonOutput := useJSONOutput(cmd, cfg)
+
+	workspace, err := resolveWorkspace(workspaceFlag, cfg, false)
+	if err != nil {
+		return err
+	}
+
+	client, err := newClient(cmd, bitbucket.WithWorkspace(workspace))
+	if err != nil {
+		return err
+	}
+
+	commits, err := client.ListSnippetCommits(workspace, snippetID)
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput {
+		return output.WriteJSON(os.Stdout, commits)
+	}
+
+	if len(commits) == 0 {
+		fmt.Println("No revisions found.")
+		return nil
+	}
+
+	tw := output.NewTableWriter(os.Stdout, "Revision", "Author", "Date", "Message")
+	for _, c := range commits {
+		tw.AddRow(
+			c.ShortHash(),
+			c.Author.Name(),
+			output.FormatRelativeTime(c.Date),
+			output.Truncate(c.Summary(), 50),
+		)
+	}
+
+	return tw.Flush()
+}
@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/kabilan108/atlas/internal/bitbucket"
+)
+
+func TestReadSnippetStdin(t *testing.T) {
+	tests := []struct {
+		name     string
+		stdin    string
+		filename string
+		want     string
+		wantErr  bool
+	}{
+		{name: "named from title", stdin: `{"level": "info"}`, want: "build-logs.json"},
+		{name: "explicit filename", stdin: "echo hi", filename: "run.sh", want: "run.sh"},
+		{name: "empty stdin", stdin: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "stdin")
+			if err := os.WriteFile(path, []byte(tt.stdin), 0600); err != nil {
+				t.Fatal(err)
+			}
+			f, err := os.Open(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			stdin := os.Stdin
+			os.Stdin = f
+			t.Cleanup(func() { os.Stdin = stdin })
+
+			filename, content, err := readSnippetStdin("Build Logs", tt.filename)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if filename != tt.want || string(content) != tt.stdin {
+				t.Errorf("got %q with %q, want %q with %q", filename, content, tt.want, tt.stdin)
+			}
+		})
+	}
+}
+
+func TestSnippetFileErrors(t *testing.T) {
+	failed := errors.New("b.txt: not found")
+
+	tests := []struct {
+		name     string
+		files    []snippetFileContent
+		wantErrs []error
+	}{
+		{"all fetched", []snippetFileContent{{name: "a.txt"}, {name: "b.txt"}}, nil},
+		{"one failed", []snippetFileContent{{name: "a.txt"}, {name: "b.txt", err: failed}}, []error{failed}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := snippetFileErrors(tt.files)
+			if tt.wantErrs == nil {
+				if err != nil {
+					t.Fatalf("err = %v, want nil", err)
+				}
+				return
+			}
+
+			var partial *bitbucket.PartialError
+			if !errors.As(err, &partial) || !reflect.DeepEqual(partial.Errors, tt.wantErrs) {
+				t.Fatalf("err = %v, want a PartialError of %v", err, tt.wantErrs)
+			}
+			if bitbucket.ExitCodeFromError(err) != bitbucket.ExitGeneralError {
+				t.Errorf("exit code = %d", bitbucket.ExitCodeFromError(err))
+			}
+		})
+	}
+}
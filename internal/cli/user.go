@@ -0,0 +1,45 @@
+package cli
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/kabilan108/atlas/internal/bitbucket"
+	"github.com/kabilan108/atlas/internal/output"
+)
+
+var (
+	currentUserMu sync.Mutex
+	currentUser   *bitbucket.User
+)
+
+func getCurrentUser(client *bitbucket.Client) (*bitbucket.User, error) {
+	currentUserMu.Lock()
+	defer currentUserMu.Unlock()
+
+	if currentUser != nil {
+		return currentUser, nil
+	}
+
+	user, err := client.GetCurrentUser()
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve 'me' to the authenticated user: %w", err)
+	}
+	currentUser = user
+	return user, nil
+}
+
+// resolveUser expands me/@me to the authenticated user's username and UUID.
+// Any other name is returned unchanged with an empty UUID.
+func resolveUser(client *bitbucket.Client, name string) (string, string, error) {
+	if !bitbucket.IsSelfAlias(name) {
+		return name, "", nil
+	}
+
+	user, err := getCurrentUser(client)
+	if err != nil {
+		return "", "", err
+	}
+	output.LogVerbose("Resolved '%s' to %s", name, user.Username)
+	return user.Username, user.UUID, nil
+}
@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+
+	"github.com/kabilan108/atlas/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// BuildInfo identifies the running binary. Version, Commit and Built are
+// injected via -ldflags at build time.
+type BuildInfo struct {
+	Version string `json:"version"`
+	Commit  string `json:"commit"`
+	Built   string `json:"built"`
+	Go      string `json:"go"`
+}
+
+func (b BuildInfo) String() string {
+	s := b.Version
+	if b.Commit != "" {
+		s += " (" + b.Commit
+		if b.Built != "" {
+			s += ", built " + b.Built
+		}
+		s += ")"
+	}
+	return s
+}
+
+func newVersionCmd(info BuildInfo) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "version",
+		Short: "Print version and build information",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			info.Go = runtime.Version()
+			if jsonOutput, _ := cmd.Flags().GetBool("json"); jsonOutput {
+				return output.WriteJSON(os.Stdout, info)
+			}
+			fmt.Printf("atlas %s %s\n", info, info.Go)
+			return nil
+		},
+	}
+
+	cmd.Flags().Bool("json", false, "Output as JSON")
+
+	return cmd
+}
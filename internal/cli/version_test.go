@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestBuildInfo(t *testing.T) {
+	tests := []struct {
+		name     string
+		info     BuildInfo
+		wantText string
+		wantJSON string
+	}{
+		{
+			name:     "version only",
+			info:     BuildInfo{Version: "dev"},
+			wantText: "dev",
+			wantJSON: `{"version":"dev","commit":"","built":"","go":""}`,
+		},
+		{
+			name:     "commit without date",
+			info:     BuildInfo{Version: "1.2.0", Commit: "abc123"},
+			wantText: "1.2.0 (abc123)",
+			wantJSON: `{"version":"1.2.0","commit":"abc123","built":"","go":""}`,
+		},
+		{
+			name:     "full metadata",
+			info:     BuildInfo{Version: "1.2.0", Commit: "abc123", Built: "2024-01-02", Go: "go1.24.0"},
+			wantText: "1.2.0 (abc123, built 2024-01-02)",
+			wantJSON: `{"version":"1.2.0","commit":"abc123","built":"2024-01-02","go":"go1.24.0"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.info.String(); got != tt.wantText {
+				t.Errorf("String() = %q, want %q", got, tt.wantText)
+			}
+			data, err := json.Marshal(tt.info)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.wantJSON {
+				t.Errorf("JSON = %s, want %s", data, tt.wantJSON)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/kabilan108/atlas/internal/bitbucket"
+	"github.com/kabilan108/atlas/internal/config"
+	"github.com/kabilan108/atlas/internal/output"
+	"github.com/spf13/cobra"
+)
+
+func newWorkspaceCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "workspace",
+		Short: "Work with workspaces",
+	}
+
+	cmd.AddCommand(newWorkspaceListCmd())
+
+	return cmd
+}
+
+func newWorkspaceListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List workspaces you have access to",
+		Args:  cobra.NoArgs,
+		RunE:  runWorkspaceList,
+	}
+
+	cmd.Flags().Bool("json", false, "Output as JSON")
+
+	return cmd
+}
+
+func runWorkspaceList(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	jsonOutput := useJSONOutput(cmd, cfg)
+
+	client, err := newClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	workspaces, err := client.ListWorkspaces()
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput {
+		return output.WriteJSON(os.Stdout, workspaces)
+	}
+
+	if len(workspaces) == 0 {
+		fmt.Println("No workspaces found.")
+		return nil
+	}
+
+	tw := output.NewTableWriter(os.Stdout, "Slug", "Name")
+	for _, ws := range workspaces {
+		tw.AddRow(ws.Slug, ws.Name)
+	}
+
+	return tw.Flush()
+}
+
+func validateWorkspace(client *bitbucket.Client, slug string) error {
+	workspaces, err := client.ListWorkspaces()
+	if err != nil {
+		return err
+	}
+
+	for _, ws := range workspaces {
+		if ws.Slug == slug {
+			return nil
+		}
+	}
+	return bitbucket.NewNotFoundError("workspace", slug)
+}
+
+func completeWorkspaces(cmd *cobra.Command) []string {
+	client, err := newClient(cmd)
+	if err != nil {
+		return nil
+	}
+
+	workspaces, err := client.ListWorkspaces()
+	if err != nil {
+		return nil
+	}
+
+	slugs := make([]string, 0, len(workspaces))
+	for _, ws := range workspaces {
+		slugs = append(slugs, ws.Slug)
+	}
+	return slugs
+}
@@ -17,13 +17,62 @@ var (
 )
 
 type Config struct {
-	Workspace   string `mapstructure:"workspace"`
+	Workspace       string `mapstructure:"workspace"`
+	Username        string `mapstructure:"username"`
+	AppPassword     string `mapstructure:"app_password"`
+	DefaultRepo     string `mapstructure:"default_repo"`
+	DefaultFormat   string `mapstructure:"default_format"`
+	PRDefaultState  string `mapstructure:"pr_default_state"`
+	PRDefaultAuthor string `mapstructure:"pr_default_author"`
+	JiraSite        string `mapstructure:"jira_site"`
+
+	// Workspaces holds per-workspace credential overrides, configured as
+	// [workspaces.<slug>] tables in config.toml.
+	Workspaces map[string]WorkspaceCredentials `mapstructure:"workspaces"`
+}
+
+type WorkspaceCredentials struct {
 	Username    string `mapstructure:"username"`
 	AppPassword string `mapstructure:"app_password"`
 }
 
+// CredentialsFor returns the username and app password to use for workspace,
+// preferring its [workspaces.<slug>] entry and falling back to the global
+// credentials field by field.
+func (c *Config) CredentialsFor(workspace string) (string, string) {
+	username, password := c.Username, c.AppPassword
+	if creds, ok := c.Workspaces[workspace]; ok {
+		if creds.Username != "" {
+			username = creds.Username
+		}
+		if creds.AppPassword != "" {
+			password = creds.AppPassword
+		}
+	}
+	return username, password
+}
+
+const (
+	localConfigName   = "atlas.json"
+	projectConfigName = ".atlas.yaml"
+)
+
+const (
+	FormatMarkdown = "markdown"
+	FormatJSON     = "json"
+	FormatAuto     = "auto"
+)
+
+var credentialKeys = []string{"username", "app_password"}
+
 var envVarPattern = regexp.MustCompile(`\$\{env:([^}]+)\}`)
 
+var fileRefPattern = regexp.MustCompile(`^\$\{file:([^}]+)\}$`)
+
+// appPasswordFileEnv names a file (e.g. a mounted Docker/Kubernetes secret)
+// whose contents take precedence over the configured app_password.
+const appPasswordFileEnv = "ATLAS_APP_PASSWORD_FILE"
+
 func ConfigDir() (string, error) {
 	home, err := os.UserHomeDir()
 	if err != nil {
@@ -50,27 +99,172 @@ func Load() (*Config, error) {
 	viper.SetConfigType("toml")
 	viper.AddConfigPath(configDir)
 
+	cfg, err := loadProjectConfig()
+	if err != nil {
+		return nil, err
+	}
+
 	if err := viper.ReadInConfig(); err != nil {
 		var configFileNotFoundError viper.ConfigFileNotFoundError
-		if errors.As(err, &configFileNotFoundError) {
-			return &Config{}, nil
+		if !errors.As(err, &configFileNotFoundError) {
+			return nil, fmt.Errorf("failed to read config: %w", err)
 		}
-		return nil, fmt.Errorf("failed to read config: %w", err)
+	} else if err := viper.Unmarshal(cfg); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
 
-	var cfg Config
-	if err := viper.Unmarshal(&cfg); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	if err := applyLocalConfig(cfg); err != nil {
+		return nil, err
 	}
 
-	cfg.AppPassword, err = expandEnvVar(cfg.AppPassword)
+	if path := os.Getenv(appPasswordFileEnv); path != "" {
+		cfg.AppPassword = "${file:" + path + "}"
+	}
+
+	cfg.AppPassword, err = expandSecret(cfg.AppPassword)
 	if err != nil {
 		return nil, err
 	}
 
+	for workspace, creds := range cfg.Workspaces {
+		creds.AppPassword, err = expandSecret(creds.AppPassword)
+		if err != nil {
+			return nil, fmt.Errorf("workspaces.%s: %w", workspace, err)
+		}
+		cfg.Workspaces[workspace] = creds
+	}
+
+	return cfg, nil
+}
+
+func LocalConfigPath() (string, bool) {
+	return findUpward(localConfigName)
+}
+
+func ProjectConfigPath() (string, bool) {
+	return findUpward(projectConfigName)
+}
+
+func findUpward(name string) (string, bool) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", false
+	}
+
+	for {
+		path := filepath.Join(dir, name)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path, true
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+func applyLocalConfig(cfg *Config) error {
+	path, ok := LocalConfigPath()
+	if !ok {
+		return nil
+	}
+
+	v := viper.New()
+	v.SetConfigFile(path)
+	v.SetConfigType("json")
+	if err := v.ReadInConfig(); err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if err := rejectCredentials(v, path); err != nil {
+		return err
+	}
+
+	var local Config
+	if err := v.Unmarshal(&local); err != nil {
+		return fmt.Errorf("failed to unmarshal %s: %w", path, err)
+	}
+
+	if local.Workspace != "" {
+		cfg.Workspace = local.Workspace
+	}
+	if local.DefaultRepo != "" {
+		cfg.DefaultRepo = local.DefaultRepo
+	}
+	if local.DefaultFormat != "" {
+		cfg.DefaultFormat = local.DefaultFormat
+	}
+	if local.PRDefaultState != "" {
+		cfg.PRDefaultState = local.PRDefaultState
+	}
+	if local.PRDefaultAuthor != "" {
+		cfg.PRDefaultAuthor = local.PRDefaultAuthor
+	}
+	if local.JiraSite != "" {
+		cfg.JiraSite = local.JiraSite
+	}
+	return nil
+}
+
+func loadProjectConfig() (*Config, error) {
+	path, ok := ProjectConfigPath()
+	if !ok {
+		return &Config{}, nil
+	}
+
+	v := viper.New()
+	v.SetConfigFile(path)
+	v.SetConfigType("yaml")
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	if err := rejectCredentials(v, path); err != nil {
+		return nil, err
+	}
+
+	var cfg Config
+	if err := v.Unmarshal(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal %s: %w", path, err)
+	}
 	return &cfg, nil
 }
 
+// rejectCredentials fails when a project file (atlas.json or .atlas.yaml)
+// sets a credential key. Those files live in the working tree and get
+// committed, so credentials belong in the user config or a secret reference.
+func rejectCredentials(v *viper.Viper, path string) error {
+	for _, key := range credentialKeys {
+		if v.IsSet(key) {
+			return fmt.Errorf("%w: %s must not contain credentials (%s); move it to the user config", ErrInvalidConfig, path, key)
+		}
+	}
+	if v.IsSet("workspaces") {
+		return fmt.Errorf("%w: %s must not contain credentials (workspaces); move it to the user config", ErrInvalidConfig, path)
+	}
+	return nil
+}
+
+// expandSecret resolves a ${file:/path} reference to the file's contents
+// (without the trailing newline) and ${env:VAR} references to their values.
+func expandSecret(value string) (string, error) {
+	matches := fileRefPattern.FindStringSubmatch(value)
+	if matches == nil {
+		return expandEnvVar(value)
+	}
+
+	data, err := os.ReadFile(matches[1])
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret file: %w", err)
+	}
+	secret := strings.TrimRight(string(data), "\r\n")
+	if secret == "" {
+		return "", fmt.Errorf("%w: secret file %s is empty", ErrInvalidConfig, matches[1])
+	}
+	return secret, nil
+}
+
 func expandEnvVar(value string) (string, error) {
 	matches := envVarPattern.FindStringSubmatch(value)
 	if matches == nil {
@@ -126,7 +320,7 @@ func GetRaw(key string) (string, bool, error) {
 	}
 
 	value := viper.GetString(key)
-	hasEnvRef := envVarPattern.MatchString(value)
+	hasEnvRef := IsEnvReference(value)
 	return value, hasEnvRef, nil
 }
 
@@ -159,16 +353,37 @@ func Set(key, value string) error {
 	return nil
 }
 
+// IsEnvReference reports whether value defers to the environment, either as
+// ${env:VAR} or ${file:/path}, rather than holding a literal secret.
 func IsEnvReference(value string) bool {
-	return envVarPattern.MatchString(value)
+	return envVarPattern.MatchString(value) || fileRefPattern.MatchString(value)
 }
 
 func ValidKeys() []string {
-	return []string{"workspace", "username", "app_password"}
+	return []string{"workspace", "username", "app_password", "default_repo", "default_format", "pr_default_state", "pr_default_author", "jira_site"}
+}
+
+// NormalizeKey maps user-typed variants such as "Default-Repo" onto the
+// canonical snake_case key.
+func NormalizeKey(key string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(key)), "-", "_")
+}
+
+// NormalizeValue strips whitespace picked up from shells and pipes, and
+// lowercases workspace slugs. app_password is returned untouched.
+func NormalizeValue(key, value string) string {
+	switch key {
+	case "app_password":
+		return value
+	case "workspace":
+		return strings.ToLower(strings.TrimSpace(value))
+	default:
+		return strings.TrimSpace(value)
+	}
 }
 
 func IsValidKey(key string) bool {
-	key = strings.ToLower(key)
+	key = NormalizeKey(key)
 	for _, valid := range ValidKeys() {
 		if key == valid {
 			return true
@@ -176,3 +391,31 @@ func IsValidKey(key string) bool {
 	}
 	return false
 }
+
+func ValidateValue(key, value string) error {
+	switch strings.ToLower(key) {
+	case "default_format":
+		if value != FormatMarkdown && value != FormatJSON && value != FormatAuto {
+			return fmt.Errorf("%w: default_format must be one of: %s, %s, %s", ErrInvalidConfig, FormatMarkdown, FormatJSON, FormatAuto)
+		}
+	case "pr_default_state":
+		if value == "" {
+			// Empty unsets the default, restoring the built-in open filter.
+			return nil
+		}
+		for _, state := range strings.Split(value, ",") {
+			if !IsValidPRState(strings.TrimSpace(state)) {
+				return fmt.Errorf("%w: pr_default_state must be a comma-separated list of: open, merged, declined, superseded", ErrInvalidConfig)
+			}
+		}
+	}
+	return nil
+}
+
+func IsValidPRState(state string) bool {
+	switch strings.ToLower(state) {
+	case "open", "merged", "declined", "superseded":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,242 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setupHome points HOME at a fresh directory and moves into an empty working
+// directory so no real or project config leaks into the test.
+func setupHome(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(appPasswordFileEnv, "")
+	t.Chdir(t.TempDir())
+	return home
+}
+
+func writeUserConfig(t *testing.T, home, content string) string {
+	t.Helper()
+	dir := filepath.Join(home, ".config", "atlas")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "config.toml")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestCredentialsFor(t *testing.T) {
+	cfg := &Config{
+		Username:    "global",
+		AppPassword: "global-pass",
+		Workspaces: map[string]WorkspaceCredentials{
+			"acme":    {Username: "acme-bot", AppPassword: "acme-pass"},
+			"partial": {AppPassword: "partial-pass"},
+		},
+	}
+
+	tests := []struct {
+		workspace    string
+		wantUser     string
+		wantPassword string
+	}{
+		{"acme", "acme-bot", "acme-pass"},
+		{"partial", "global", "partial-pass"},
+		{"other", "global", "global-pass"},
+		{"", "global", "global-pass"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.workspace, func(t *testing.T) {
+			user, password := cfg.CredentialsFor(tt.workspace)
+			if user != tt.wantUser || password != tt.wantPassword {
+				t.Errorf("CredentialsFor(%q) = %q, %q; want %q, %q", tt.workspace, user, password, tt.wantUser, tt.wantPassword)
+			}
+		})
+	}
+}
+
+func TestLoadWorkspaceCredentials(t *testing.T) {
+	home := setupHome(t)
+	writeUserConfig(t, home, `workspace = "main"
+username = "global"
+app_password = "global-pass"
+
+[workspaces.acme]
+username = "acme-bot"
+app_password = "acme-pass"
+`)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	if user, password := cfg.CredentialsFor("acme"); user != "acme-bot" || password != "acme-pass" {
+		t.Errorf("acme credentials = %q, %q; want the workspace override", user, password)
+	}
+	if user, password := cfg.CredentialsFor("main"); user != "global" || password != "global-pass" {
+		t.Errorf("main credentials = %q, %q; want the global credentials", user, password)
+	}
+}
+
+func TestValidateValue(t *testing.T) {
+	tests := []struct {
+		key     string
+		value   string
+		wantErr bool
+	}{
+		{"default_format", FormatJSON, false},
+		{"default_format", FormatAuto, false},
+		{"default_format", "yaml", true},
+		{"pr_default_state", "open", false},
+		{"pr_default_state", "open, merged", false},
+		{"pr_default_state", "OPEN", false},
+		{"pr_default_state", "", false},
+		{"pr_default_state", "closed", true},
+		{"pr_default_state", "open,", true},
+		{"jira_site", "anything", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key+"="+tt.value, func(t *testing.T) {
+			err := ValidateValue(tt.key, tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateValue(%q, %q) error = %v, wantErr %v", tt.key, tt.value, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPRListDefaults(t *testing.T) {
+	tests := []struct {
+		name       string
+		local      string
+		wantState  string
+		wantAuthor string
+	}{
+		{"user config", "", "merged,declined", "@me"},
+		{"atlas.json overrides", `{"pr_default_state": "open"}`, "open", "@me"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupHome(t)
+			if err := Set("pr_default_state", "merged,declined"); err != nil {
+				t.Fatal(err)
+			}
+			if err := Set("pr_default_author", "@me"); err != nil {
+				t.Fatal(err)
+			}
+			if tt.local != "" {
+				dir, _ := os.Getwd()
+				writeFile(t, filepath.Join(dir, localConfigName), tt.local)
+			}
+
+			cfg, err := Load()
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			if cfg.PRDefaultState != tt.wantState || cfg.PRDefaultAuthor != tt.wantAuthor {
+				t.Errorf("pr defaults = %q, %q; want %q, %q", cfg.PRDefaultState, cfg.PRDefaultAuthor, tt.wantState, tt.wantAuthor)
+			}
+		})
+	}
+}
+
+func TestNormalizeKeyAndValue(t *testing.T) {
+	tests := []struct {
+		key       string
+		value     string
+		wantKey   string
+		wantValue string
+	}{
+		{" Default-Repo ", " api \n", "default_repo", "api"},
+		{"WORKSPACE", "  Acme-Corp\n", "workspace", "acme-corp"},
+		{"app-password", "  s3cret \n", "app_password", "  s3cret \n"},
+		{"jira_site", "Example.atlassian.net ", "jira_site", "Example.atlassian.net"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			key := NormalizeKey(tt.key)
+			if key != tt.wantKey {
+				t.Errorf("NormalizeKey(%q) = %q, want %q", tt.key, key, tt.wantKey)
+			}
+			if !IsValidKey(tt.key) {
+				t.Errorf("IsValidKey(%q) = false", tt.key)
+			}
+			if got := NormalizeValue(key, tt.value); got != tt.wantValue {
+				t.Errorf("NormalizeValue(%q, %q) = %q, want %q", key, tt.value, got, tt.wantValue)
+			}
+		})
+	}
+}
+
+func TestLoadSecretReferences(t *testing.T) {
+	tests := []struct {
+		name         string
+		appPassword  string
+		fileContent  string
+		passwordFile bool
+		env          string
+		want         string
+		wantErr      error
+	}{
+		{name: "literal", appPassword: "plain", want: "plain"},
+		{name: "env reference", appPassword: "${env:ATLAS_TEST_TOKEN}", env: "from-env", want: "from-env"},
+		{name: "missing env var", appPassword: "${env:ATLAS_TEST_TOKEN}", wantErr: ErrMissingEnvVar},
+		{name: "file reference trims newline", appPassword: "${file:SECRET}", fileContent: "from-file\r\n", want: "from-file"},
+		{name: "empty secret file", appPassword: "${file:SECRET}", fileContent: "\n", wantErr: ErrInvalidConfig},
+		{name: "password file env overrides config", appPassword: "plain", fileContent: "mounted\n", passwordFile: true, want: "mounted"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := setupHome(t)
+			t.Setenv("ATLAS_TEST_TOKEN", tt.env)
+
+			secret := filepath.Join(t.TempDir(), "secret")
+			if tt.fileContent != "" {
+				if err := os.WriteFile(secret, []byte(tt.fileContent), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.passwordFile {
+				t.Setenv(appPasswordFileEnv, secret)
+			}
+			password := strings.ReplaceAll(tt.appPassword, "SECRET", secret)
+			writeUserConfig(t, home, "username = \"alice\"\napp_password = \""+password+"\"\n")
+
+			cfg, err := Load()
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Load err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cfg.AppPassword != tt.want {
+				t.Errorf("app_password = %q, want %q", cfg.AppPassword, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadMissingSecretFile(t *testing.T) {
+	home := setupHome(t)
+	writeUserConfig(t, home, "app_password = \"${file:"+filepath.Join(home, "absent")+"}\"\n")
+
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "failed to read secret file") {
+		t.Errorf("Load err = %v, want a secret file read error", err)
+	}
+}
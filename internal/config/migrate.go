@@ -0,0 +1,159 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/viper"
+)
+
+var legacyKeys = map[string]string{
+	"atlassian_email":     "username",
+	"email":               "username",
+	"user":                "username",
+	"atlassian_token":     "app_password",
+	"api_token":           "app_password",
+	"app_token":           "app_password",
+	"password":            "app_password",
+	"bitbucket_workspace": "workspace",
+}
+
+// nestedKeys are table-valued settings that migrate copies verbatim rather
+// than flattening to strings.
+var nestedKeys = map[string]bool{
+	"workspaces": true,
+}
+
+type MigrateResult struct {
+	Changed bool
+	Renamed map[string]string
+	Dropped []string
+	Backups []string
+}
+
+func legacyJSONPath() (string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config.json"), nil
+}
+
+func Migrate() (*MigrateResult, error) {
+	tomlPath, err := ConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	jsonPath, err := legacyJSONPath()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &MigrateResult{Renamed: make(map[string]string)}
+	normalized := make(map[string]string)
+	nested := make(map[string]any)
+	var sources []string
+
+	// config.json is read first so values in config.toml take precedence
+	for _, path := range []string{jsonPath, tomlPath} {
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+
+		settings, err := readSettings(path)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, path)
+
+		changed := path == jsonPath
+		fileValues := make(map[string]string)
+		for key, value := range settings {
+			if IsValidKey(key) {
+				fileValues[key] = fmt.Sprint(value)
+			}
+		}
+		for key, value := range settings {
+			if IsValidKey(key) {
+				continue
+			}
+			if nestedKeys[key] {
+				nested[key] = value
+				continue
+			}
+			changed = true
+			newKey, ok := legacyKeys[key]
+			if !ok {
+				result.Dropped = append(result.Dropped, key)
+				continue
+			}
+			result.Renamed[key] = newKey
+			if _, exists := fileValues[newKey]; !exists {
+				fileValues[newKey] = fmt.Sprint(value)
+			}
+		}
+
+		for key, value := range fileValues {
+			normalized[key] = value
+		}
+		if changed {
+			result.Changed = true
+		}
+	}
+
+	if !result.Changed {
+		return result, nil
+	}
+	sort.Strings(result.Dropped)
+
+	for _, path := range sources {
+		backup := path + ".bak"
+		if err := copyFile(path, backup); err != nil {
+			return nil, fmt.Errorf("failed to back up %s: %w", path, err)
+		}
+		result.Backups = append(result.Backups, backup)
+	}
+
+	v := viper.New()
+	v.SetConfigType("toml")
+	for key, value := range normalized {
+		v.Set(key, value)
+	}
+	for key, value := range nested {
+		v.Set(key, value)
+	}
+	if err := v.WriteConfigAs(tomlPath); err != nil {
+		return nil, fmt.Errorf("failed to write config: %w", err)
+	}
+	if err := os.Chmod(tomlPath, 0600); err != nil {
+		return nil, fmt.Errorf("failed to set config permissions: %w", err)
+	}
+
+	if _, err := os.Stat(jsonPath); err == nil {
+		if err := os.Remove(jsonPath); err != nil {
+			return nil, fmt.Errorf("failed to remove legacy config: %w", err)
+		}
+	}
+
+	sort.Strings(result.Dropped)
+	return result, nil
+}
+
+func readSettings(path string) (map[string]any, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return v.AllSettings(), nil
+}
+
+func copyFile(src, dst string) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(dst, data, 0600)
+}
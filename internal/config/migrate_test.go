@@ -0,0 +1,146 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMigrateLegacyConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		json        string
+		toml        string
+		wantChanged bool
+		wantRenamed map[string]string
+		wantDropped []string
+		wantBackups []string
+		want        Config
+	}{
+		{
+			name:        "legacy json",
+			json:        `{"atlassian_email": "me@example.com", "atlassian_token": "tok", "workspace": "acme", "theme": "dark", "colour": "red"}`,
+			wantChanged: true,
+			wantRenamed: map[string]string{"atlassian_email": "username", "atlassian_token": "app_password"},
+			wantDropped: []string{"colour", "theme"},
+			wantBackups: []string{"config.json.bak"},
+			want:        Config{Username: "me@example.com", AppPassword: "tok", Workspace: "acme"},
+		},
+		{
+			name:        "toml wins over json",
+			json:        `{"workspace": "old", "email": "old@example.com"}`,
+			toml:        "workspace = \"new\"\n",
+			wantChanged: true,
+			wantRenamed: map[string]string{"email": "username"},
+			wantBackups: []string{"config.json.bak", "config.toml.bak"},
+			want:        Config{Username: "old@example.com", Workspace: "new"},
+		},
+		{
+			name:        "canonical key wins over legacy alias",
+			toml:        "username = \"current\"\nuser = \"legacy\"\n",
+			wantChanged: true,
+			wantRenamed: map[string]string{"user": "username"},
+			wantBackups: []string{"config.toml.bak"},
+			want:        Config{Username: "current"},
+		},
+		{
+			name:        "already current",
+			toml:        "username = \"me\"\nworkspace = \"acme\"\n",
+			wantRenamed: map[string]string{},
+			want:        Config{Username: "me", Workspace: "acme"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := setupHome(t)
+			dir := filepath.Join(home, ".config", "atlas")
+			if tt.toml != "" {
+				writeUserConfig(t, home, tt.toml)
+			}
+			if tt.json != "" {
+				if err := os.MkdirAll(dir, 0700); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(tt.json), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			result, err := Migrate()
+			if err != nil {
+				t.Fatalf("Migrate: %v", err)
+			}
+			if result.Changed != tt.wantChanged {
+				t.Errorf("Changed = %v, want %v", result.Changed, tt.wantChanged)
+			}
+			if !reflect.DeepEqual(result.Renamed, tt.wantRenamed) {
+				t.Errorf("Renamed = %v, want %v", result.Renamed, tt.wantRenamed)
+			}
+			if !reflect.DeepEqual(result.Dropped, tt.wantDropped) {
+				t.Errorf("Dropped = %v, want %v", result.Dropped, tt.wantDropped)
+			}
+
+			var backups []string
+			for _, backup := range result.Backups {
+				backups = append(backups, filepath.Base(backup))
+			}
+			if !reflect.DeepEqual(backups, tt.wantBackups) {
+				t.Errorf("Backups = %v, want %v", backups, tt.wantBackups)
+			}
+			if tt.json != "" {
+				if data, err := os.ReadFile(filepath.Join(dir, "config.json.bak")); err != nil || string(data) != tt.json {
+					t.Errorf("config.json.bak = %q, %v; want the original file", data, err)
+				}
+				if _, err := os.Stat(filepath.Join(dir, "config.json")); !os.IsNotExist(err) {
+					t.Errorf("config.json still exists after migrate")
+				}
+			}
+
+			cfg, err := Load()
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			got := Config{Username: cfg.Username, AppPassword: cfg.AppPassword, Workspace: cfg.Workspace}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("config after migrate = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMigratePreservesWorkspaceCredentials(t *testing.T) {
+	home := setupHome(t)
+	writeUserConfig(t, home, `atlassian_email = "me@example.com"
+app_password = "global-pass"
+
+[workspaces.acme]
+username = "acme-bot"
+app_password = "acme-pass"
+`)
+
+	result, err := Migrate()
+	if err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+	if !result.Changed {
+		t.Fatal("expected the legacy key to be migrated")
+	}
+	for _, key := range result.Dropped {
+		if key == "workspaces" {
+			t.Errorf("workspaces table was dropped")
+		}
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Username != "me@example.com" {
+		t.Errorf("username = %q, want the renamed legacy value", cfg.Username)
+	}
+	if user, password := cfg.CredentialsFor("acme"); user != "acme-bot" || password != "acme-pass" {
+		t.Errorf("acme credentials after migrate = %q, %q; want acme-bot, acme-pass", user, password)
+	}
+}
@@ -0,0 +1,151 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLocalConfigOverlay(t *testing.T) {
+	home := setupHome(t)
+	writeUserConfig(t, home, `workspace = "user-ws"
+username = "me"
+app_password = "secret"
+default_repo = "user-repo"
+`)
+
+	project := t.TempDir()
+	writeFile(t, filepath.Join(project, localConfigName), `{"workspace": "local-ws", "default_repo": "local-repo"}`)
+	nested := filepath.Join(project, "a", "b")
+	if err := os.MkdirAll(nested, 0700); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(nested)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Workspace != "local-ws" || cfg.DefaultRepo != "local-repo" {
+		t.Errorf("workspace, default_repo = %q, %q; want the atlas.json values", cfg.Workspace, cfg.DefaultRepo)
+	}
+	if cfg.Username != "me" || cfg.AppPassword != "secret" {
+		t.Errorf("credentials = %q, %q; want the user config values", cfg.Username, cfg.AppPassword)
+	}
+}
+
+func TestProjectFilesRejectCredentials(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{"atlas.json username", localConfigName, `{"username": "me"}`},
+		{"atlas.json app_password", localConfigName, `{"app_password": "secret"}`},
+		{"atlas.json workspaces", localConfigName, `{"workspaces": {"acme": {"app_password": "secret"}}}`},
+		{".atlas.yaml app_password", projectConfigName, "app_password: secret\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := setupHome(t)
+			writeUserConfig(t, home, `username = "me"
+app_password = "secret"
+`)
+			dir := t.TempDir()
+			writeFile(t, filepath.Join(dir, tt.file), tt.content)
+			t.Chdir(dir)
+
+			_, err := Load()
+			if !errors.Is(err, ErrInvalidConfig) {
+				t.Fatalf("Load err = %v, want ErrInvalidConfig", err)
+			}
+		})
+	}
+}
+
+func TestProjectConfigDiscovery(t *testing.T) {
+	tests := []struct {
+		name     string
+		project  string
+		user     string
+		want     Config
+		wantPath bool
+	}{
+		{
+			name:     "project supplies defaults",
+			project:  "workspace: team\ndefault_repo: mono\ndefault_format: json\n",
+			user:     "username = \"me\"\n",
+			want:     Config{Workspace: "team", DefaultRepo: "mono", DefaultFormat: "json"},
+			wantPath: true,
+		},
+		{
+			name:     "user config wins",
+			project:  "workspace: team\npr_default_state: merged\n",
+			user:     "workspace = \"mine\"\n",
+			want:     Config{Workspace: "mine", PRDefaultState: "merged"},
+			wantPath: true,
+		},
+		{
+			name: "no project file",
+			user: "workspace = \"mine\"\n",
+			want: Config{Workspace: "mine"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := setupHome(t)
+			writeUserConfig(t, home, tt.user)
+
+			root := t.TempDir()
+			if tt.project != "" {
+				writeFile(t, filepath.Join(root, projectConfigName), tt.project)
+			}
+			nested := filepath.Join(root, "services", "api")
+			if err := os.MkdirAll(nested, 0700); err != nil {
+				t.Fatal(err)
+			}
+			t.Chdir(nested)
+
+			path, ok := ProjectConfigPath()
+			if ok != tt.wantPath {
+				t.Fatalf("ProjectConfigPath found = %v, want %v", ok, tt.wantPath)
+			}
+			if ok {
+				// t.TempDir may sit behind a symlink, so compare resolved paths.
+				got, _ := filepath.EvalSymlinks(filepath.Dir(path))
+				want, _ := filepath.EvalSymlinks(root)
+				if got != want {
+					t.Errorf("ProjectConfigPath = %q, want it in %q", path, root)
+				}
+			}
+
+			cfg, err := Load()
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			got := Config{
+				Workspace:      cfg.Workspace,
+				DefaultRepo:    cfg.DefaultRepo,
+				DefaultFormat:  cfg.DefaultFormat,
+				PRDefaultState: cfg.PRDefaultState,
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("config = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
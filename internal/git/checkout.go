@@ -1,11 +1,27 @@
 package git
 
 import (
+	"errors"
 	"fmt"
 	"os/exec"
 )
 
+var ErrGitNotInstalled = errors.New("git executable not found in PATH")
+
+// RequireGit reports ErrGitNotInstalled when there is no git binary to run,
+// so callers can fail with an actionable message instead of exec's.
+func RequireGit() error {
+	if _, err := exec.LookPath("git"); err != nil {
+		return ErrGitNotInstalled
+	}
+	return nil
+}
+
 func FetchAndCheckout(remote, branch string) error {
+	if err := RequireGit(); err != nil {
+		return fmt.Errorf("%w: git is required for checkout; install it, or fetch the branch %q manually", err, branch)
+	}
+
 	fetchCmd := exec.Command("git", "fetch", remote, branch)
 	if output, err := fetchCmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("git fetch failed: %s", output)
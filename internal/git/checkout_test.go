@@ -0,0 +1,46 @@
+package git
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRequireGit(t *testing.T) {
+	fakeBin := t.TempDir()
+	if err := os.WriteFile(filepath.Join(fakeBin, "git"), []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		wantErr error
+	}{
+		{"git on PATH", fakeBin, nil},
+		{"git missing", t.TempDir(), ErrGitNotInstalled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("PATH", tt.path)
+			if err := RequireGit(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("RequireGit() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFetchAndCheckoutWithoutGit(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+
+	err := FetchAndCheckout("origin", "feature/x")
+	if !errors.Is(err, ErrGitNotInstalled) {
+		t.Fatalf("err = %v, want ErrGitNotInstalled", err)
+	}
+	if !strings.Contains(err.Error(), `"feature/x"`) {
+		t.Errorf("error %q does not name the branch to fetch", err)
+	}
+}
@@ -20,6 +20,8 @@ var (
 var (
 	sshPattern   = regexp.MustCompile(`^git@bitbucket\.org:([^/]+)/([^/]+?)(?:\.git)?$`)
 	httpsPattern = regexp.MustCompile(`^https://(?:[^@]+@)?bitbucket\.org/([^/]+)/([^/]+?)(?:\.git)?$`)
+	webPattern   = regexp.MustCompile(`^https://bitbucket\.org/([^/]+)/([^/?#]+)(?:[/?#].*)?$`)
+	slugPattern  = regexp.MustCompile(`^([\w.-]+)/([\w.-]+)$`)
 )
 
 func InferRepository() (workspace string, repo string, err error) {
@@ -54,6 +56,28 @@ func ParseRemoteURL(url string) (workspace string, repo string, err error) {
 	return "", "", ErrNotBitbucketRemote
 }
 
+// ParseRepoRef accepts "workspace/repo", a clone URL, or any bitbucket.org
+// web URL inside the repository.
+func ParseRepoRef(ref string) (workspace string, repo string, err error) {
+	ref = strings.TrimSpace(ref)
+
+	if matches := slugPattern.FindStringSubmatch(ref); matches != nil {
+		return matches[1], matches[2], nil
+	}
+
+	// Web URLs first: the clone URL pattern would keep a query string or
+	// fragment as part of the repo name.
+	if matches := webPattern.FindStringSubmatch(ref); matches != nil {
+		return matches[1], strings.TrimSuffix(matches[2], ".git"), nil
+	}
+
+	if workspace, repo, err := ParseRemoteURL(ref); err == nil {
+		return workspace, repo, nil
+	}
+
+	return "", "", fmt.Errorf("%w: %s (expected workspace/repo or a bitbucket.org URL)", ErrInvalidRemoteURL, ref)
+}
+
 func findGitDir() (string, error) {
 	dir, err := os.Getwd()
 	if err != nil {
@@ -0,0 +1,42 @@
+package git
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseRepoRef(t *testing.T) {
+	tests := []struct {
+		ref       string
+		workspace string
+		repo      string
+		wantErr   bool
+	}{
+		{ref: "ws/repo", workspace: "ws", repo: "repo"},
+		{ref: "  ws/my.repo  ", workspace: "ws", repo: "my.repo"},
+		{ref: "git@bitbucket.org:ws/repo.git", workspace: "ws", repo: "repo"},
+		{ref: "https://user@bitbucket.org/ws/repo.git", workspace: "ws", repo: "repo"},
+		{ref: "https://bitbucket.org/ws/repo/pull-requests/7", workspace: "ws", repo: "repo"},
+		{ref: "https://bitbucket.org/ws/repo?at=main", workspace: "ws", repo: "repo"},
+		{ref: "repo", wantErr: true},
+		{ref: "https://github.com/ws/repo", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			workspace, repo, err := ParseRepoRef(tt.ref)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidRemoteURL) {
+					t.Fatalf("err = %v, want ErrInvalidRemoteURL", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if workspace != tt.workspace || repo != tt.repo {
+				t.Errorf("got %s/%s, want %s/%s", workspace, repo, tt.workspace, tt.repo)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+var extensionLanguages = map[string]string{
+	".bash":  "bash",
+	".c":     "c",
+	".cpp":   "cpp",
+	".cs":    "csharp",
+	".css":   "css",
+	".go":    "go",
+	".h":     "c",
+	".html":  "html",
+	".java":  "java",
+	".js":    "javascript",
+	".json":  "json",
+	".jsx":   "jsx",
+	".kt":    "kotlin",
+	".md":    "markdown",
+	".php":   "php",
+	".py":    "python",
+	".rb":    "ruby",
+	".rs":    "rust",
+	".scala": "scala",
+	".sh":    "bash",
+	".sql":   "sql",
+	".swift": "swift",
+	".toml":  "toml",
+	".ts":    "typescript",
+	".tsx":   "tsx",
+	".xml":   "xml",
+	".yaml":  "yaml",
+	".yml":   "yaml",
+}
+
+var filenameLanguages = map[string]string{
+	"dockerfile": "dockerfile",
+	"makefile":   "makefile",
+}
+
+// LanguageForFile guesses a code fence language from a filename, returning ""
+// when the extension is unknown.
+func LanguageForFile(filename string) string {
+	base := strings.ToLower(filepath.Base(filename))
+	if lang, ok := filenameLanguages[base]; ok {
+		return lang
+	}
+	return extensionLanguages[filepath.Ext(base)]
+}
+
+// DetectExtension guesses a file extension for unnamed content such as piped
+// stdin, falling back to ".txt".
+func DetectExtension(content []byte) string {
+	trimmed := bytes.TrimSpace(content)
+	switch {
+	case len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') && json.Valid(trimmed):
+		return ".json"
+	case bytes.HasPrefix(trimmed, []byte("diff --git")) || bytes.HasPrefix(trimmed, []byte("--- ")):
+		return ".diff"
+	case bytes.HasPrefix(trimmed, []byte("#!")) && bytes.Contains(bytes.SplitN(trimmed, []byte("\n"), 2)[0], []byte("sh")):
+		return ".sh"
+	}
+	return ".txt"
+}
+
+// WriteCodeBlock writes content as a fenced markdown block headed by the
+// filename. The fence is lengthened when the content itself contains backticks.
+func WriteCodeBlock(w io.Writer, filename, content string) error {
+	fence := "```"
+	for strings.Contains(content, fence) {
+		fence += "`"
+	}
+
+	content = strings.TrimRight(content, "\n")
+	_, err := fmt.Fprintf(w, "### %s\n\n%s%s\n%s\n%s\n\n", filename, fence, LanguageForFile(filename), content, fence)
+	return err
+}
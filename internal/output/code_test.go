@@ -0,0 +1,92 @@
+package output
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLanguageForFile(t *testing.T) {
+	tests := []struct {
+		filename string
+		want     string
+	}{
+		{"main.go", "go"},
+		{"src/App.TSX", "tsx"},
+		{"config.yml", "yaml"},
+		{"Dockerfile", "dockerfile"},
+		{"build/Makefile", "makefile"},
+		{"notes", ""},
+		{"archive.tar.gz", ""},
+	}
+
+	for _, tt := range tests {
+		if got := LanguageForFile(tt.filename); got != tt.want {
+			t.Errorf("LanguageForFile(%q) = %q, want %q", tt.filename, got, tt.want)
+		}
+	}
+}
+
+func TestWriteCodeBlock(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		content  string
+		want     string
+	}{
+		{
+			name:     "language from extension",
+			filename: "main.go",
+			content:  "package main\n\n",
+			want:     "### main.go\n\n```go\npackage main\n```\n\n",
+		},
+		{
+			name:     "unknown extension",
+			filename: "notes.txt",
+			content:  "hello",
+			want:     "### notes.txt\n\n```\nhello\n```\n\n",
+		},
+		{
+			name:     "fence longer than embedded backticks",
+			filename: "README.md",
+			content:  "```sh\nmake\n```",
+			want:     "### README.md\n\n````markdown\n```sh\nmake\n```\n````\n\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sb strings.Builder
+			if err := WriteCodeBlock(&sb, tt.filename, tt.content); err != nil {
+				t.Fatal(err)
+			}
+			if got := sb.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectExtension(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"json object", "  {\"a\": 1}\n", ".json"},
+		{"json array", "[1, 2]", ".json"},
+		{"invalid json", "{not json", ".txt"},
+		{"git diff", "diff --git a/x b/x\n", ".diff"},
+		{"unified diff", "--- a/x\n+++ b/x\n", ".diff"},
+		{"shell script", "#!/bin/bash\necho hi\n", ".sh"},
+		{"other shebang", "#!/usr/bin/env python3\nprint()\n", ".txt"},
+		{"plain text", "just some logs", ".txt"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectExtension([]byte(tt.content)); got != tt.want {
+				t.Errorf("DetectExtension(%q) = %q, want %q", tt.content, got, tt.want)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package output
 
 import (
 	"fmt"
+	"html"
 	"io"
 	"sort"
 	"strings"
@@ -11,21 +12,64 @@ import (
 	"github.com/kabilan108/atlas/internal/bitbucket"
 )
 
+const (
+	CommentSortPath    = "path"
+	CommentSortCreated = "created"
+	CommentSortUpdated = "updated"
+
+	CommentGroupFile   = "file"
+	CommentGroupAuthor = "author"
+	CommentGroupNone   = "none"
+)
+
+// CommentOrder controls how comment threads are sectioned and ordered. The
+// zero value matches the default: grouped by file and line, sorted by path.
+type CommentOrder struct {
+	Sort    string
+	GroupBy string
+}
+
+// ParseCommentOrder validates the --comments-sort and --group-by values.
+func ParseCommentOrder(sortBy, groupBy string) (CommentOrder, error) {
+	switch sortBy {
+	case "", CommentSortPath, CommentSortCreated, CommentSortUpdated:
+	default:
+		return CommentOrder{}, fmt.Errorf("invalid comment sort %q (valid: %s, %s, %s)", sortBy, CommentSortCreated, CommentSortUpdated, CommentSortPath)
+	}
+	switch groupBy {
+	case "", CommentGroupFile, CommentGroupAuthor, CommentGroupNone:
+	default:
+		return CommentOrder{}, fmt.Errorf("invalid comment grouping %q (valid: %s, %s, %s)", groupBy, CommentGroupFile, CommentGroupAuthor, CommentGroupNone)
+	}
+	return CommentOrder{Sort: sortBy, GroupBy: groupBy}, nil
+}
+
 type CommentWriter struct {
-	w          io.Writer
-	prAuthorID string
-	converter  *md.Converter
-	diffParser *DiffParser
+	w            io.Writer
+	prAuthorID   string
+	converter    *md.Converter
+	diffParser   *DiffParser
+	contextLines int
+	order        CommentOrder
 }
 
 func NewCommentWriter(w io.Writer, prAuthorID string) *CommentWriter {
 	return &CommentWriter{
-		w:          w,
-		prAuthorID: prAuthorID,
-		converter:  md.NewConverter("", true, nil),
+		w:            w,
+		prAuthorID:   prAuthorID,
+		converter:    NewMarkdownConverter(),
+		contextLines: DefaultContextLines,
 	}
 }
 
+func (cw *CommentWriter) SetContextLines(n int) {
+	cw.contextLines = ClampContextLines(n)
+}
+
+func (cw *CommentWriter) SetOrder(order CommentOrder) {
+	cw.order = order
+}
+
 func (cw *CommentWriter) SetDiff(diff []byte) {
 	cw.diffParser = NewDiffParser()
 	cw.diffParser.Parse(diff)
@@ -38,8 +82,8 @@ func (cw *CommentWriter) WriteComments(comments []bitbucket.Comment, includeReso
 		return nil
 	}
 
-	grouped := cw.groupByLocation(filtered)
-	cw.writeGroupedComments(grouped, filtered)
+	groups := cw.groupComments(rootComments(filtered))
+	cw.writeGroupedComments(groups, filtered)
 	return nil
 }
 
@@ -62,88 +106,193 @@ type locationKey struct {
 	line int
 }
 
-func (cw *CommentWriter) groupByLocation(comments []bitbucket.Comment) map[locationKey][]bitbucket.Comment {
-	grouped := make(map[locationKey][]bitbucket.Comment)
+func commentLocation(c bitbucket.Comment) locationKey {
+	key := locationKey{}
+	if c.Inline != nil {
+		key.path = c.Inline.Path
+		if c.Inline.To != nil {
+			key.line = *c.Inline.To
+		} else if c.Inline.From != nil {
+			key.line = *c.Inline.From
+		}
+	}
+	return key
+}
 
-	commentMap := make(map[int]bitbucket.Comment)
+// rootComments returns the comments that start a thread. Replies whose parent
+// was filtered out (resolved or deleted) are promoted to roots so they aren't
+// lost.
+func rootComments(comments []bitbucket.Comment) []bitbucket.Comment {
+	ids := make(map[int]bool)
 	for _, c := range comments {
-		commentMap[c.ID] = c
+		ids[c.ID] = true
 	}
 
+	var roots []bitbucket.Comment
 	for _, c := range comments {
-		if c.Parent != nil {
+		if c.Parent != nil && ids[c.Parent.ID] {
 			continue
 		}
+		roots = append(roots, c)
+	}
+	return roots
+}
 
-		key := locationKey{}
-		if c.Inline != nil {
-			key.path = c.Inline.Path
-			if c.Inline.To != nil {
-				key.line = *c.Inline.To
-			} else if c.Inline.From != nil {
-				key.line = *c.Inline.From
+// commentGroup is one section of the comments output: a file location, an
+// author, or (with CommentGroupNone) everything.
+type commentGroup struct {
+	location locationKey
+	author   string
+	roots    []bitbucket.Comment
+}
+
+func (cw *CommentWriter) groupComments(roots []bitbucket.Comment) []commentGroup {
+	var groups []commentGroup
+	switch cw.order.GroupBy {
+	case CommentGroupNone:
+		groups = []commentGroup{{roots: roots}}
+
+	case CommentGroupAuthor:
+		index := make(map[string]int)
+		for _, c := range roots {
+			i, ok := index[c.User.Username]
+			if !ok {
+				i = len(groups)
+				index[c.User.Username] = i
+				groups = append(groups, commentGroup{author: c.User.Username})
 			}
+			groups[i].roots = append(groups[i].roots, c)
 		}
+		sort.Slice(groups, func(i, j int) bool {
+			return strings.ToLower(groups[i].author) < strings.ToLower(groups[j].author)
+		})
 
-		grouped[key] = append(grouped[key], c)
+	default:
+		index := make(map[locationKey]int)
+		for _, c := range roots {
+			key := commentLocation(c)
+			i, ok := index[key]
+			if !ok {
+				i = len(groups)
+				index[key] = i
+				groups = append(groups, commentGroup{location: key})
+			}
+			groups[i].roots = append(groups[i].roots, c)
+		}
+		sort.Slice(groups, func(i, j int) bool {
+			if groups[i].location.path != groups[j].location.path {
+				return groups[i].location.path < groups[j].location.path
+			}
+			return groups[i].location.line < groups[j].location.line
+		})
 	}
 
-	return grouped
+	for _, g := range groups {
+		cw.sortThreads(g.roots)
+	}
+	return groups
 }
 
-func (cw *CommentWriter) writeGroupedComments(grouped map[locationKey][]bitbucket.Comment, allComments []bitbucket.Comment) {
-	commentMap := make(map[int]bitbucket.Comment)
-	for _, c := range allComments {
-		commentMap[c.ID] = c
+// sortThreads orders thread roots within a group; replies always stay under
+// their root in API order. Sorting is stable so ties keep API order.
+func (cw *CommentWriter) sortThreads(roots []bitbucket.Comment) {
+	switch cw.order.Sort {
+	case CommentSortCreated:
+		sort.SliceStable(roots, func(i, j int) bool {
+			return roots[i].CreatedOn.Before(roots[j].CreatedOn)
+		})
+	case CommentSortUpdated:
+		sort.SliceStable(roots, func(i, j int) bool {
+			return roots[i].UpdatedOn.After(roots[j].UpdatedOn)
+		})
+	default:
+		sort.SliceStable(roots, func(i, j int) bool {
+			a, b := commentLocation(roots[i]), commentLocation(roots[j])
+			if a.path != b.path {
+				return a.path < b.path
+			}
+			return a.line < b.line
+		})
 	}
+}
 
-	var keys []locationKey
-	for k := range grouped {
-		keys = append(keys, k)
-	}
-	sort.Slice(keys, func(i, j int) bool {
-		if keys[i].path != keys[j].path {
-			return keys[i].path < keys[j].path
+func (cw *CommentWriter) writeGroupedComments(groups []commentGroup, allComments []bitbucket.Comment) {
+	replies := make(map[int][]bitbucket.Comment)
+	for _, c := range allComments {
+		if c.Parent != nil {
+			replies[c.Parent.ID] = append(replies[c.Parent.ID], c)
 		}
-		return keys[i].line < keys[j].line
-	})
+	}
+	visited := make(map[int]bool)
 
 	fmt.Fprintln(cw.w, "## Comments")
 	fmt.Fprintln(cw.w)
 
-	for _, key := range keys {
-		comments := grouped[key]
-
-		if key.path != "" {
-			fmt.Fprint(cw.w, FormatFileLineHeader(key.path, key.line))
+	byFile := cw.order.GroupBy != CommentGroupAuthor && cw.order.GroupBy != CommentGroupNone
+	for _, g := range groups {
+		switch {
+		case !byFile:
+			if g.author != "" {
+				fmt.Fprintf(cw.w, "#### @%s\n\n", g.author)
+			}
+		case g.location.path == "":
+			// General discussion sorts first; set it apart from the file comments
+			if len(groups) > 1 {
+				fmt.Fprintln(cw.w, "#### General")
+				fmt.Fprintln(cw.w)
+			}
+		default:
+			fmt.Fprint(cw.w, FormatFileLineHeader(g.location.path, g.location.line))
 			fmt.Fprintln(cw.w)
+			cw.writeLocationContext(g.location)
+		}
 
-			if cw.diffParser != nil && key.line > 0 {
-				hunk := cw.diffParser.GetHunkForLine(key.path, key.line)
-				if hunk != nil {
-					fmt.Fprint(cw.w, hunk.FormatContext(key.line, 3))
-					fmt.Fprintln(cw.w)
-				}
+		for _, root := range g.roots {
+			// Outside file grouping each inline thread names its own location.
+			if !byFile && root.Inline != nil {
+				key := commentLocation(root)
+				fmt.Fprintln(cw.w, formatLocation(key))
+				fmt.Fprintln(cw.w)
+				cw.writeLocationContext(key)
 			}
+			cw.writeThread(root, replies, visited, 0)
 		}
+	}
+}
 
-		for _, parent := range comments {
-			cw.writeComment(parent, 0)
+func (cw *CommentWriter) writeLocationContext(key locationKey) {
+	if cw.diffParser == nil || key.line <= 0 {
+		return
+	}
+	if hunk := cw.diffParser.GetHunkForLine(key.path, key.line); hunk != nil {
+		fmt.Fprint(cw.w, hunk.FormatContext(key.line, cw.contextLines))
+		fmt.Fprintln(cw.w)
+	}
+}
 
-			for _, c := range allComments {
-				if c.Parent != nil && c.Parent.ID == parent.ID {
-					cw.writeComment(c, 1)
-				}
-			}
-		}
+func formatLocation(key locationKey) string {
+	if key.line > 0 {
+		return fmt.Sprintf("`%s:%d`", key.path, key.line)
 	}
+	return fmt.Sprintf("`%s` (file comment)", key.path)
 }
 
-func (cw *CommentWriter) writeComment(c bitbucket.Comment, depth int) {
-	indent := ""
-	if depth > 0 {
-		indent = "> "
+// writeThread renders c and its replies depth-first, nesting each reply level
+// one blockquote deeper. visited guards against cyclic parent references.
+func (cw *CommentWriter) writeThread(c bitbucket.Comment, replies map[int][]bitbucket.Comment, visited map[int]bool, depth int) {
+	if visited[c.ID] {
+		return
+	}
+	visited[c.ID] = true
+
+	cw.writeComment(c, depth)
+	for _, reply := range replies[c.ID] {
+		cw.writeThread(reply, replies, visited, depth+1)
 	}
+}
+
+func (cw *CommentWriter) writeComment(c bitbucket.Comment, depth int) {
+	indent := strings.Repeat("> ", depth)
 
 	authorIndicator := ""
 	if c.User.UUID == cw.prAuthorID || c.User.AccountID == cw.prAuthorID {
@@ -161,7 +310,7 @@ func (cw *CommentWriter) writeComment(c bitbucket.Comment, depth int) {
 
 	fmt.Fprintf(cw.w, "%s**@%s**%s (%s)%s:\n", indent, c.User.Username, authorIndicator, timestamp, status)
 
-	content := cw.convertContent(c.Content)
+	content := SanitizeText(cw.convertContent(c.Content))
 	for _, line := range strings.Split(content, "\n") {
 		fmt.Fprintf(cw.w, "%s%s\n", indent, line)
 	}
@@ -176,7 +325,7 @@ func (cw *CommentWriter) convertContent(content bitbucket.Content) string {
 		}
 	}
 	if content.Raw != "" {
-		return strings.TrimSpace(content.Raw)
+		return strings.TrimSpace(html.UnescapeString(content.Raw))
 	}
 	return ""
 }
@@ -0,0 +1,224 @@
+package output
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kabilan108/atlas/internal/bitbucket"
+)
+
+func testComment(id int, user, text string, inline *bitbucket.Inline) bitbucket.Comment {
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC).Add(time.Duration(id) * time.Minute)
+	return bitbucket.Comment{
+		ID:        id,
+		Content:   bitbucket.Content{Raw: text},
+		User:      bitbucket.User{Username: user},
+		CreatedOn: created,
+		UpdatedOn: created,
+		Inline:    inline,
+	}
+}
+
+func TestFormatFileLineHeader(t *testing.T) {
+	tests := []struct {
+		path string
+		line int
+		want string
+	}{
+		{"main.go", 12, "#### `main.go:12`\n"},
+		{"main.go", 0, "#### `main.go` (file comment)\n"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatFileLineHeader(tt.path, tt.line); got != tt.want {
+			t.Errorf("FormatFileLineHeader(%q, %d) = %q, want %q", tt.path, tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestWriteCommentsFileLevel(t *testing.T) {
+	line := 3
+	tests := []struct {
+		name     string
+		comments []bitbucket.Comment
+		want     []string
+		notWant  []string
+	}{
+		{
+			name: "general and file comments get separate headers",
+			comments: []bitbucket.Comment{
+				testComment(1, "alice", "overall looks good", nil),
+				testComment(2, "bob", "rename this file", &bitbucket.Inline{Path: "main.go"}),
+				testComment(3, "carol", "off by one", &bitbucket.Inline{Path: "main.go", To: &line}),
+			},
+			want: []string{"#### General", "#### `main.go` (file comment)", "#### `main.go:3`"},
+		},
+		{
+			name: "general only has no header",
+			comments: []bitbucket.Comment{
+				testComment(1, "alice", "overall looks good", nil),
+			},
+			notWant: []string{"#### General"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sb strings.Builder
+			if err := NewCommentWriter(&sb, "").WriteComments(tt.comments, false); err != nil {
+				t.Fatal(err)
+			}
+			got := sb.String()
+
+			last := -1
+			for _, s := range tt.want {
+				i := strings.Index(got, s)
+				if i < 0 {
+					t.Fatalf("missing %q in:\n%s", s, got)
+				}
+				if i < last {
+					t.Errorf("%q out of order in:\n%s", s, got)
+				}
+				last = i
+			}
+			for _, s := range tt.notWant {
+				if strings.Contains(got, s) {
+					t.Errorf("unexpected %q in:\n%s", s, got)
+				}
+			}
+		})
+	}
+}
+
+func TestWriteCommentsDecodesRawEntities(t *testing.T) {
+	var sb strings.Builder
+	comments := []bitbucket.Comment{testComment(1, "alice", "a &lt; b &amp; &#39;c&#39;", nil)}
+	if err := NewCommentWriter(&sb, "").WriteComments(comments, false); err != nil {
+		t.Fatal(err)
+	}
+	if got := sb.String(); !strings.Contains(got, "\na < b & 'c'\n") {
+		t.Errorf("entities not decoded:\n%s", got)
+	}
+}
+
+func TestWriteCommentsReplyDepth(t *testing.T) {
+	reply := func(c bitbucket.Comment, parent int) bitbucket.Comment {
+		c.Parent = &bitbucket.Parent{ID: parent}
+		return c
+	}
+	deleted := testComment(4, "dave", "gone", nil)
+	deleted.Deleted = true
+
+	comments := []bitbucket.Comment{
+		testComment(1, "alice", "root", nil),
+		reply(testComment(2, "bob", "first reply", nil), 1),
+		reply(testComment(3, "carol", "nested reply", nil), 2),
+		deleted,
+		reply(testComment(5, "erin", "orphaned reply", nil), 4),
+	}
+
+	var sb strings.Builder
+	if err := NewCommentWriter(&sb, "").WriteComments(comments, false); err != nil {
+		t.Fatal(err)
+	}
+	got := sb.String()
+
+	for _, want := range []string{
+		"\n**@alice** (",
+		"\n> **@bob** (",
+		"\n> > nested reply\n",
+		"\n> > **@carol** (",
+		"\n**@erin** (",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q in:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "gone") {
+		t.Errorf("deleted comment rendered:\n%s", got)
+	}
+}
+
+func TestParseCommentOrder(t *testing.T) {
+	tests := []struct {
+		sort, groupBy string
+		wantErr       bool
+	}{
+		{"", "", false},
+		{CommentSortCreated, CommentGroupAuthor, false},
+		{CommentSortUpdated, CommentGroupNone, false},
+		{"newest", "", true},
+		{"", "thread", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sort+"/"+tt.groupBy, func(t *testing.T) {
+			order, err := ParseCommentOrder(tt.sort, tt.groupBy)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && (order.Sort != tt.sort || order.GroupBy != tt.groupBy) {
+				t.Errorf("order = %+v", order)
+			}
+		})
+	}
+}
+
+func TestWriteCommentsOrder(t *testing.T) {
+	lineA, lineB := 5, 2
+	// IDs set creation order; bob's thread is the most recently updated.
+	comments := []bitbucket.Comment{
+		testComment(1, "carol", "on b.go", &bitbucket.Inline{Path: "b.go", To: &lineB}),
+		testComment(2, "bob", "on a.go", &bitbucket.Inline{Path: "a.go", To: &lineA}),
+		testComment(3, "alice", "general", nil),
+	}
+	comments[1].UpdatedOn = comments[1].UpdatedOn.Add(time.Hour)
+
+	tests := []struct {
+		name  string
+		order CommentOrder
+		want  []string
+	}{
+		{
+			name: "default groups by file sorted by path",
+			want: []string{"#### General", "general", "#### `a.go:5`", "on a.go", "#### `b.go:2`", "on b.go"},
+		},
+		{
+			name:  "group by author",
+			order: CommentOrder{GroupBy: CommentGroupAuthor},
+			want:  []string{"#### @alice", "general", "#### @bob", "`a.go:5`", "on a.go", "#### @carol", "`b.go:2`", "on b.go"},
+		},
+		{
+			name:  "ungrouped by creation time",
+			order: CommentOrder{Sort: CommentSortCreated, GroupBy: CommentGroupNone},
+			want:  []string{"on b.go", "on a.go", "general"},
+		},
+		{
+			name:  "ungrouped by last update",
+			order: CommentOrder{Sort: CommentSortUpdated, GroupBy: CommentGroupNone},
+			want:  []string{"on a.go", "general", "on b.go"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sb strings.Builder
+			cw := NewCommentWriter(&sb, "")
+			cw.SetOrder(tt.order)
+			if err := cw.WriteComments(comments, false); err != nil {
+				t.Fatal(err)
+			}
+			got := sb.String()
+
+			last := -1
+			for _, s := range tt.want {
+				i := strings.Index(got[last+1:], s)
+				if i < 0 {
+					t.Fatalf("%q missing or out of order in:\n%s", s, got)
+				}
+				last += 1 + i
+			}
+		})
+	}
+}
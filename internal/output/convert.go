@@ -0,0 +1,61 @@
+package output
+
+import (
+	"strings"
+
+	md "github.com/JohannesKaufmann/html-to-markdown"
+	"github.com/JohannesKaufmann/html-to-markdown/plugin"
+	"github.com/PuerkitoBio/goquery"
+)
+
+func NewMarkdownConverter() *md.Converter {
+	converter := md.NewConverter("", true, nil)
+	converter.Use(plugin.TaskListItems())
+	converter.Before(normalizeTaskListItems)
+	converter.AddRules(mentionRule())
+	return converter
+}
+
+func mentionRule() md.Rule {
+	return md.Rule{
+		Filter: []string{"span", "a"},
+		Replacement: func(content string, selec *goquery.Selection, opt *md.Options) *string {
+			if !selec.HasClass("ap-mention") {
+				return nil
+			}
+			name := strings.TrimSpace(selec.Text())
+			if name == "" {
+				return nil
+			}
+			return md.String("@" + strings.TrimPrefix(name, "@"))
+		},
+	}
+}
+
+// Bitbucket marks task-list items with a state attribute instead of a
+// checkbox input, so inject one for the task-list plugin to render.
+func normalizeTaskListItems(selec *goquery.Selection) {
+	selec.Find("li").Each(func(_ int, li *goquery.Selection) {
+		if li.ChildrenFiltered("input[type=checkbox]").Length() > 0 {
+			return
+		}
+
+		state, hasState := li.Attr("data-task-state")
+		isTask := hasState || li.HasClass("ap-task-list-item") || li.HasClass("task-list-item")
+		if !isTask {
+			return
+		}
+
+		checked := li.HasClass("checked")
+		switch strings.ToUpper(state) {
+		case "DONE", "RESOLVED", "CHECKED":
+			checked = true
+		}
+
+		input := `<input type="checkbox">`
+		if checked {
+			input = `<input type="checkbox" checked>`
+		}
+		li.PrependHtml(input)
+	})
+}
@@ -0,0 +1,34 @@
+package output
+
+import (
+	"testing"
+)
+
+func TestMarkdownConverter(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want string
+	}{
+		{"mention span", `<p>Thanks <span class="ap-mention">@Alice Smith</span>!</p>`, "Thanks @Alice Smith!"},
+		{"mention link without at", `<p>cc <a class="ap-mention" href="/alice">Alice</a></p>`, "cc @Alice"},
+		{"plain link", `<p><a href="https://example.com">docs</a></p>`, "[docs](https://example.com)"},
+		{"task state", `<ul><li data-task-state="TODO">write tests</li><li data-task-state="DONE">ship</li></ul>`, "- [ ] write tests\n- [x] ship"},
+		{"task class", `<ul><li class="task-list-item checked">done</li><li class="ap-task-list-item">open</li></ul>`, "- [x] done\n- [ ] open"},
+		{"existing checkbox", `<ul><li><input type="checkbox" checked>kept</li></ul>`, "- [x] kept"},
+		{"plain list", `<ul><li>one</li><li>two</li></ul>`, "- one\n- two"},
+	}
+
+	converter := NewMarkdownConverter()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := converter.ConvertString(tt.html)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("ConvertString(%q) = %q, want %q", tt.html, got, tt.want)
+			}
+		})
+	}
+}
@@ -95,6 +95,27 @@ func (p *DiffParser) Parse(diff []byte) error {
 	return scanner.Err()
 }
 
+// FileDiff reconstructs the unified diff for a single file from its parsed
+// hunks, or returns "" if the file has no changes in the diff.
+func (p *DiffParser) FileDiff(filePath string) string {
+	hunks, ok := p.hunks[filePath]
+	if !ok {
+		return ""
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- a/%s\n+++ b/%s\n", filePath, filePath)
+	for _, hunk := range hunks {
+		sb.WriteString(hunk.Header)
+		sb.WriteString("\n")
+		for _, line := range hunk.Lines {
+			sb.WriteString(line)
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
+
 func (p *DiffParser) GetHunkForLine(filePath string, lineNum int) *DiffHunk {
 	hunks, ok := p.hunks[filePath]
 	if !ok {
@@ -192,7 +213,22 @@ func (h *DiffHunk) findContextRange(targetLine int, contextLines int) (int, int)
 	return start, end
 }
 
-func FormatDiffContext(diff []byte, filePath string, lineNum int) string {
+const (
+	DefaultContextLines = 3
+	MaxContextLines     = 50
+)
+
+func ClampContextLines(n int) int {
+	if n < 0 {
+		return 0
+	}
+	if n > MaxContextLines {
+		return MaxContextLines
+	}
+	return n
+}
+
+func FormatDiffContext(diff []byte, filePath string, lineNum int, contextLines int) string {
 	parser := NewDiffParser()
 	if err := parser.Parse(diff); err != nil {
 		return ""
@@ -203,12 +239,12 @@ func FormatDiffContext(diff []byte, filePath string, lineNum int) string {
 		return ""
 	}
 
-	return hunk.FormatContext(lineNum, 3)
+	return hunk.FormatContext(lineNum, ClampContextLines(contextLines))
 }
 
 func FormatFileLineHeader(path string, line int) string {
 	if line > 0 {
 		return fmt.Sprintf("#### `%s:%d`\n", path, line)
 	}
-	return fmt.Sprintf("#### `%s`\n", path)
+	return fmt.Sprintf("#### `%s` (file comment)\n", path)
 }
@@ -0,0 +1,96 @@
+package output
+
+import (
+	"testing"
+)
+
+const testDiff = `diff --git a/main.go b/main.go
+index 1111111..2222222 100644
+--- a/main.go
++++ b/main.go
+@@ -1,7 +1,8 @@
+ package main
+ 
+ import "fmt"
+ 
+ func main() {
+-	fmt.Println("hi")
++	fmt.Println("hello")
++	fmt.Println("world")
+ }
+diff --git a/README.md b/README.md
+index 3333333..4444444 100644
+--- a/README.md
++++ b/README.md
+@@ -1 +1 @@
+-# Old
++# New
+`
+
+func TestClampContextLines(t *testing.T) {
+	tests := []struct {
+		in, want int
+	}{
+		{-1, 0},
+		{0, 0},
+		{DefaultContextLines, DefaultContextLines},
+		{MaxContextLines, MaxContextLines},
+		{MaxContextLines + 1, MaxContextLines},
+	}
+
+	for _, tt := range tests {
+		if got := ClampContextLines(tt.in); got != tt.want {
+			t.Errorf("ClampContextLines(%d) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFormatDiffContext(t *testing.T) {
+	const header = "```diff\n@@ -1,7 +1,8 @@\n"
+
+	tests := []struct {
+		name    string
+		file    string
+		line    int
+		context int
+		want    string
+	}{
+		{"no context", "main.go", 7, 0, header + "+\tfmt.Println(\"world\")\n```\n"},
+		{"one line", "main.go", 7, 1, header + "+\tfmt.Println(\"hello\")\n+\tfmt.Println(\"world\")\n }\n```\n"},
+		{"clamped to hunk", "main.go", 1, 2, header + " package main\n \n import \"fmt\"\n```\n"},
+		{"negative treated as zero", "main.go", 1, -5, header + " package main\n```\n"},
+		{"line outside diff", "main.go", 40, 3, ""},
+		{"file not in diff", "other.go", 1, 3, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatDiffContext([]byte(testDiff), tt.file, tt.line, tt.context); got != tt.want {
+				t.Errorf("FormatDiffContext(%s:%d, %d) =\n%q\nwant\n%q", tt.file, tt.line, tt.context, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFileDiff(t *testing.T) {
+	p := NewDiffParser()
+	if err := p.Parse([]byte(testDiff)); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"README.md", "--- a/README.md\n+++ b/README.md\n@@ -1 +1 @@\n-# Old\n+# New\n"},
+		{"missing.go", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := p.FileDiff(tt.path); got != tt.want {
+				t.Errorf("FileDiff(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package output
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const maxSlugLen = 80
+
+var slugInvalidChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+func Slugify(s string) string {
+	slug := slugInvalidChars.ReplaceAllString(strings.ToLower(s), "-")
+	slug = strings.Trim(slug, "-")
+	if len(slug) > maxSlugLen {
+		slug = strings.TrimRight(slug[:maxSlugLen], "-")
+	}
+	if slug == "" {
+		return "document"
+	}
+	return slug
+}
+
+func CreateDocumentFile(dir, name, ext string) (*os.File, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	base := Slugify(name)
+	for i := 0; ; i++ {
+		filename := base + ext
+		if i > 0 {
+			filename = fmt.Sprintf("%s-%d%s", base, i, ext)
+		}
+
+		f, err := os.OpenFile(filepath.Join(dir, filename), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if errors.Is(err, fs.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to create output file: %w", err)
+		}
+		return f, nil
+	}
+}
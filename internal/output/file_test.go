@@ -0,0 +1,50 @@
+package output
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"Fix login bug", "fix-login-bug"},
+		{"  PROJ-12: Add  OAuth!! ", "proj-12-add-oauth"},
+		{"../../etc/passwd", "etc-passwd"},
+		{"日本語", "document"},
+		{"", "document"},
+		{strings.Repeat("a", 100), strings.Repeat("a", 80)},
+		{strings.Repeat("a", 79) + " b", strings.Repeat("a", 79)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			if got := Slugify(tt.in); got != tt.want {
+				t.Errorf("Slugify(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateDocumentFileAvoidsCollisions(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "out")
+
+	var names []string
+	for range 3 {
+		f, err := CreateDocumentFile(dir, "PR 7: Add feature", ".md")
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Close()
+		names = append(names, filepath.Base(f.Name()))
+	}
+
+	want := []string{"pr-7-add-feature.md", "pr-7-add-feature-1.md", "pr-7-add-feature-2.md"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("files = %v, want %v", names, want)
+	}
+}
@@ -0,0 +1,29 @@
+package output
+
+import "sync/atomic"
+
+type Glyphs struct {
+	Arrow string
+	Check string
+	Cross string
+}
+
+var (
+	unicodeGlyphs = Glyphs{Arrow: "→", Check: "✓", Cross: "✗"}
+	asciiGlyphs   = Glyphs{Arrow: "->", Check: "+", Cross: "x"}
+
+	asciiMode atomic.Bool
+)
+
+// SetASCII switches every writer to plain ASCII glyphs for terminals that
+// are not UTF-8.
+func SetASCII(enabled bool) {
+	asciiMode.Store(enabled)
+}
+
+func CurrentGlyphs() Glyphs {
+	if asciiMode.Load() {
+		return asciiGlyphs
+	}
+	return unicodeGlyphs
+}
@@ -0,0 +1,45 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestASCIIGlyphs(t *testing.T) {
+	tests := []struct {
+		name  string
+		ascii bool
+		want  string
+	}{
+		{"unicode", false, "**Branch**: feature/x → main"},
+		{"ascii", true, "**Branch**: feature/x -> main"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetASCII(tt.ascii)
+			t.Cleanup(func() { SetASCII(false) })
+
+			var buf bytes.Buffer
+			if err := NewPRMarkdownWriter(&buf).WritePR(testPR(t)); err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(buf.String(), tt.want) {
+				t.Errorf("output missing %q:\n%s", tt.want, buf.String())
+			}
+
+			if !tt.ascii {
+				return
+			}
+			g := CurrentGlyphs()
+			for _, s := range []string{g.Arrow, g.Check, g.Cross, buf.String()} {
+				for _, r := range s {
+					if r > 0x7f {
+						t.Errorf("non-ASCII rune %q in ASCII mode", r)
+					}
+				}
+			}
+		})
+	}
+}
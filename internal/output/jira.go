@@ -0,0 +1,34 @@
+package output
+
+import (
+	"regexp"
+	"strings"
+)
+
+var jiraKeyPattern = regexp.MustCompile(`\b[A-Z][A-Z0-9]+-[1-9][0-9]*\b`)
+
+// ExtractJiraKeys returns the Jira-style issue keys (PROJ-123) found in texts,
+// deduplicated in order of first appearance.
+func ExtractJiraKeys(texts ...string) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, text := range texts {
+		for _, key := range jiraKeyPattern.FindAllString(text, -1) {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	return keys
+}
+
+// JiraIssueURL builds the browse URL for key on site, which may be given with
+// or without a scheme (e.g. "mycompany.atlassian.net").
+func JiraIssueURL(site, key string) string {
+	site = strings.TrimRight(site, "/")
+	if !strings.Contains(site, "://") {
+		site = "https://" + site
+	}
+	return site + "/browse/" + key
+}
@@ -0,0 +1,73 @@
+package output
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExtractJiraKeys(t *testing.T) {
+	tests := []struct {
+		name  string
+		texts []string
+		want  []string
+	}{
+		{"none", []string{"fix typo", ""}, nil},
+		{"dedup in order", []string{"PROJ-1: fix", "also OPS-22 and PROJ-1"}, []string{"PROJ-1", "OPS-22"}},
+		{"branch name", []string{"feature/AB2-7-login"}, []string{"AB2-7"}},
+		{"not keys", []string{"proj-1, PROJ-0, X-1, UTF-8ish, PROJ-"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExtractJiraKeys(tt.texts...); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExtractJiraKeys(%q) = %q, want %q", tt.texts, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJiraIssueURL(t *testing.T) {
+	tests := []struct {
+		site string
+		want string
+	}{
+		{"acme.atlassian.net", "https://acme.atlassian.net/browse/PROJ-1"},
+		{"https://acme.atlassian.net/", "https://acme.atlassian.net/browse/PROJ-1"},
+		{"http://jira.internal", "http://jira.internal/browse/PROJ-1"},
+	}
+
+	for _, tt := range tests {
+		if got := JiraIssueURL(tt.site, "PROJ-1"); got != tt.want {
+			t.Errorf("JiraIssueURL(%q) = %q, want %q", tt.site, got, tt.want)
+		}
+	}
+}
+
+func TestWritePRLinkedIssues(t *testing.T) {
+	tests := []struct {
+		name string
+		site string
+		want bool
+	}{
+		{"site configured", "acme.atlassian.net", true},
+		{"no site", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			m := NewPRMarkdownWriter(&buf)
+			m.SetJiraSite(tt.site)
+			if err := m.WritePR(testPR(t)); err != nil {
+				t.Fatal(err)
+			}
+
+			section := "## Linked Issues\n\n- [PROJ-1](https://acme.atlassian.net/browse/PROJ-1)\n"
+			if got := strings.Contains(buf.String(), section); got != tt.want {
+				t.Errorf("linked issues present = %v, want %v:\n%s", got, tt.want, buf.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteRawJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{"object", `{"id":1,"links":{"html":{"href":"u"}}}`, "{\n  \"id\": 1,\n  \"links\": {\n    \"html\": {\n      \"href\": \"u\"\n    }\n  }\n}\n", false},
+		{"keeps key order", `{"z":1,"a":2}`, "{\n  \"z\": 1,\n  \"a\": 2\n}\n", false},
+		{"keeps html", `{"html":"<p>&amp;</p>"}`, "{\n  \"html\": \"<p>&amp;</p>\"\n}\n", false},
+		{"invalid", `{"id":`, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := WriteRawJSON(&buf, []byte(tt.in))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("WriteRawJSON = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
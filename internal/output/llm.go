@@ -0,0 +1,67 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+type LLMDocument struct {
+	Title   string
+	Source  string
+	Content string
+}
+
+// bundleTagEscaper neutralizes the bundle's own tags inside document text, so
+// a body quoting "</content>" cannot end its document early.
+var bundleTagEscaper = strings.NewReplacer(
+	"<document", "&lt;document", "</document", "&lt;/document",
+	"<index", "&lt;index", "</index", "&lt;/index",
+	"<source", "&lt;source", "</source", "&lt;/source",
+	"<content", "&lt;content", "</content", "&lt;/content",
+)
+
+// WriteLLMBundle packages documents into one prompt-ready block: an index
+// followed by each document with stable <source>/<content> delimiters. Titles,
+// sources and content are sanitized, since the bundle is fed to other tools.
+func WriteLLMBundle(w io.Writer, docs []LLMDocument) error {
+	var sb strings.Builder
+
+	sb.WriteString("<documents>\n<index>\n")
+	for i, doc := range docs {
+		fmt.Fprintf(&sb, "%d. %s (%s)\n", i+1, bundleLine(doc.Title), bundleLine(doc.Source))
+	}
+	sb.WriteString("</index>\n")
+
+	for i, doc := range docs {
+		fmt.Fprintf(&sb, "<document index=\"%d\">\n", i+1)
+		fmt.Fprintf(&sb, "<source>%s</source>\n", bundleLine(doc.Source))
+		sb.WriteString("<content>\n")
+		sb.WriteString(strings.TrimRight(bundleTagEscaper.Replace(SanitizeText(doc.Content)), "\n"))
+		sb.WriteString("\n</content>\n</document>\n")
+	}
+	sb.WriteString("</documents>\n")
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func bundleLine(s string) string {
+	return bundleTagEscaper.Replace(SanitizeLine(s))
+}
+
+// TruncateContent caps content at maxBytes without splitting a UTF-8
+// sequence and appends a marker with how much was dropped. A maxBytes of zero
+// or less disables truncation.
+func TruncateContent(content string, maxBytes int) string {
+	if maxBytes <= 0 || len(content) <= maxBytes {
+		return content
+	}
+
+	cut := maxBytes
+	for cut > 0 && !utf8.RuneStart(content[cut]) {
+		cut--
+	}
+	return fmt.Sprintf("%s\n\n[truncated %d of %d bytes]\n", content[:cut], len(content)-cut, len(content))
+}
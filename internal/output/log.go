@@ -0,0 +1,133 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	LogFormatText = "text"
+	LogFormatJSON = "json"
+)
+
+type Logger struct {
+	mu      sync.Mutex
+	w       io.Writer
+	format  string
+	verbose bool
+	quiet   bool
+}
+
+type logEntry struct {
+	Level string    `json:"level"`
+	Msg   string    `json:"msg"`
+	TS    time.Time `json:"ts"`
+}
+
+var defaultLogger = NewLogger(os.Stderr)
+
+func NewLogger(w io.Writer) *Logger {
+	return &Logger{w: w, format: LogFormatText}
+}
+
+func (l *Logger) SetFormat(format string) error {
+	switch format {
+	case LogFormatText, LogFormatJSON:
+	default:
+		return fmt.Errorf("invalid log format: %s (valid formats: text, json)", format)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.format = format
+	return nil
+}
+
+func (l *Logger) SetVerbose(verbose bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.verbose = verbose
+}
+
+func (l *Logger) SetQuiet(quiet bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.quiet = quiet
+}
+
+func (l *Logger) IsQuiet() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.quiet
+}
+
+func (l *Logger) SetOutput(w io.Writer) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.w = w
+}
+
+func (l *Logger) Error(format string, args ...any) {
+	l.log("error", "Error: ", fmt.Sprintf(format, args...))
+}
+
+func (l *Logger) Warn(format string, args ...any) {
+	if l.IsQuiet() {
+		return
+	}
+	l.log("warn", "Warning: ", fmt.Sprintf(format, args...))
+}
+
+func (l *Logger) Info(format string, args ...any) {
+	if l.IsQuiet() {
+		return
+	}
+	l.log("info", "", fmt.Sprintf(format, args...))
+}
+
+func (l *Logger) Verbose(format string, args ...any) {
+	l.mu.Lock()
+	enabled := l.verbose && !l.quiet
+	l.mu.Unlock()
+
+	if enabled {
+		l.log("verbose", "", fmt.Sprintf(format, args...))
+	}
+}
+
+func (l *Logger) log(level, prefix, msg string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.format == LogFormatJSON {
+		encoder := json.NewEncoder(l.w)
+		encoder.SetEscapeHTML(false)
+		encoder.Encode(logEntry{Level: level, Msg: msg, TS: time.Now()})
+		return
+	}
+	fmt.Fprintf(l.w, "%s%s\n", prefix, msg)
+}
+
+func DefaultLogger() *Logger {
+	return defaultLogger
+}
+
+func LogError(format string, args ...any) {
+	defaultLogger.Error(format, args...)
+}
+
+func LogWarn(format string, args ...any) {
+	defaultLogger.Warn(format, args...)
+}
+
+func LogInfo(format string, args ...any) {
+	defaultLogger.Info(format, args...)
+}
+
+func LogVerbose(format string, args ...any) {
+	defaultLogger.Verbose(format, args...)
+}
@@ -0,0 +1,109 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestLoggerFormats(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		verbose bool
+		quiet   bool
+		log     func(l *Logger)
+		want    string
+	}{
+		{"text warn", LogFormatText, false, false, func(l *Logger) { l.Warn("slow %s", "api") }, "Warning: slow api\n"},
+		{"text error", LogFormatText, false, false, func(l *Logger) { l.Error("boom") }, "Error: boom\n"},
+		{"verbose disabled", LogFormatText, false, false, func(l *Logger) { l.Verbose("detail") }, ""},
+		{"verbose enabled", LogFormatText, true, false, func(l *Logger) { l.Verbose("detail") }, "detail\n"},
+		{"quiet drops info", LogFormatText, false, true, func(l *Logger) { l.Info("hello") }, ""},
+		{"quiet keeps errors", LogFormatText, false, true, func(l *Logger) { l.Error("boom") }, "Error: boom\n"},
+		{"quiet wins over verbose", LogFormatText, true, true, func(l *Logger) { l.Verbose("detail") }, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			l := NewLogger(&buf)
+			if err := l.SetFormat(tt.format); err != nil {
+				t.Fatal(err)
+			}
+			l.SetVerbose(tt.verbose)
+			l.SetQuiet(tt.quiet)
+
+			tt.log(l)
+			if got := buf.String(); got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoggerJSONLine(t *testing.T) {
+	var stderr bytes.Buffer
+	l := NewLogger(&stderr)
+	if err := l.SetFormat(LogFormatJSON); err != nil {
+		t.Fatal(err)
+	}
+
+	l.Warn("rate limit low: %d <remaining>", 5)
+
+	lines := strings.Split(strings.TrimSuffix(stderr.String(), "\n"), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("got %d lines, want one JSON line: %q", len(lines), stderr.String())
+	}
+
+	var entry logEntry
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatalf("line is not JSON: %v: %s", err, lines[0])
+	}
+	if entry.Level != "warn" {
+		t.Errorf("level = %q, want warn", entry.Level)
+	}
+	if entry.Msg != "rate limit low: 5 <remaining>" {
+		t.Errorf("msg = %q, want the unprefixed, unescaped message", entry.Msg)
+	}
+	if entry.TS.IsZero() {
+		t.Error("ts is missing")
+	}
+}
+
+func TestLoggerRejectsUnknownFormat(t *testing.T) {
+	if err := NewLogger(&bytes.Buffer{}).SetFormat("yaml"); err == nil {
+		t.Error("SetFormat(yaml) succeeded, want an error")
+	}
+}
+
+func TestQuietLogger(t *testing.T) {
+	tests := []struct {
+		level string
+		log   func(l *Logger)
+		shown bool
+	}{
+		{"error", func(l *Logger) { l.Error("boom") }, true},
+		{"warn", func(l *Logger) { l.Warn("careful") }, false},
+		{"info", func(l *Logger) { l.Info("hello") }, false},
+		{"verbose", func(l *Logger) { l.Verbose("detail") }, false},
+	}
+
+	for _, format := range []string{LogFormatText, LogFormatJSON} {
+		for _, tt := range tests {
+			t.Run(format+"/"+tt.level, func(t *testing.T) {
+				var buf bytes.Buffer
+				l := NewLogger(&buf)
+				l.SetFormat(format)
+				l.SetVerbose(true)
+				l.SetQuiet(true)
+
+				tt.log(l)
+				if shown := buf.Len() > 0; shown != tt.shown {
+					t.Errorf("%s shown = %v under --quiet, want %v (%q)", tt.level, shown, tt.shown, buf.String())
+				}
+			})
+		}
+	}
+}
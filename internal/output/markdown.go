@@ -1,45 +1,121 @@
 package output
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"strings"
+	"time"
 
 	"github.com/kabilan108/atlas/internal/bitbucket"
 )
 
 type PRMarkdownWriter struct {
-	w io.Writer
+	w           io.Writer
+	frontmatter bool
+	metaFields  []string
+	readiness   *bitbucket.MergeReadiness
+	approvals   map[string]time.Time
+	jiraSite    string
 }
 
+type metadataField struct {
+	key   string
+	value string
+}
+
+var PRMetadataFields = []string{"id", "title", "author", "state", "source", "destination", "url", "created", "updated"}
+
 func NewPRMarkdownWriter(w io.Writer) *PRMarkdownWriter {
 	return &PRMarkdownWriter{w: w}
 }
 
+func (m *PRMarkdownWriter) SetFrontmatter(enabled bool) {
+	m.frontmatter = enabled
+}
+
+func (m *PRMarkdownWriter) SetMergeReadiness(readiness *bitbucket.MergeReadiness) {
+	m.readiness = readiness
+}
+
+// SetApprovals supplies approval times keyed by username; approved reviewers
+// with a known time render as "approved 2 days ago".
+func (m *PRMarkdownWriter) SetApprovals(approvals map[string]time.Time) {
+	m.approvals = approvals
+}
+
+// SetJiraSite enables the Linked Issues section, linking Jira keys found in
+// the PR title, description and source branch to this site.
+func (m *PRMarkdownWriter) SetJiraSite(site string) {
+	m.jiraSite = site
+}
+
+func (m *PRMarkdownWriter) SetMetaFields(fields []string) {
+	m.metaFields = fields
+}
+
 func (m *PRMarkdownWriter) WritePR(pr *bitbucket.PullRequest) error {
-	fmt.Fprintf(m.w, "# PR #%d: %s\n\n", pr.ID, pr.Title)
+	if m.frontmatter {
+		fields := m.metaFields
+		if len(fields) == 0 {
+			fields = PRMetadataFields
+		}
+		m.writeFrontmatter(buildPRMetadata(pr, fields))
+	}
+
+	fmt.Fprintf(m.w, "# PR #%d: %s\n\n", pr.ID, SanitizeLine(pr.Title))
 	fmt.Fprintf(m.w, "**Author**: @%s\n", pr.Author.Username)
 	fmt.Fprintf(m.w, "**State**: %s\n", pr.State)
-	fmt.Fprintf(m.w, "**Branch**: %s → %s\n", pr.Source.Branch.Name, pr.Destination.Branch.Name)
+	fmt.Fprintf(m.w, "**Branch**: %s %s %s\n", SanitizeLine(pr.Source.Branch.Name), CurrentGlyphs().Arrow, SanitizeLine(pr.Destination.Branch.Name))
 
 	reviewerStatus := m.formatReviewers(pr)
 	if reviewerStatus != "" {
 		fmt.Fprintf(m.w, "**Reviewers**: %s\n", reviewerStatus)
 	}
 
+	if m.readiness != nil {
+		fmt.Fprintf(m.w, "**Merge**: %s\n", formatMergeReadiness(m.readiness))
+	}
+
 	fmt.Fprintln(m.w)
 
 	if pr.Description != "" {
 		fmt.Fprintln(m.w, "## Description")
 		fmt.Fprintln(m.w)
-		fmt.Fprintln(m.w, pr.Description)
+		fmt.Fprintln(m.w, SanitizeText(pr.Description))
 		fmt.Fprintln(m.w)
 	}
 
+	if m.jiraSite != "" {
+		m.writeLinkedIssues(pr)
+	}
+
 	m.writeFooter(pr)
 	return nil
 }
 
+func (m *PRMarkdownWriter) writeLinkedIssues(pr *bitbucket.PullRequest) {
+	keys := ExtractJiraKeys(pr.Title, pr.Description, pr.Source.Branch.Name)
+	if len(keys) == 0 {
+		return
+	}
+
+	fmt.Fprintln(m.w, "## Linked Issues")
+	fmt.Fprintln(m.w)
+	for _, key := range keys {
+		fmt.Fprintf(m.w, "- [%s](%s)\n", key, JiraIssueURL(m.jiraSite, key))
+	}
+	fmt.Fprintln(m.w)
+}
+
+func formatMergeReadiness(r *bitbucket.MergeReadiness) string {
+	glyphs := CurrentGlyphs()
+	if r.Ready {
+		return glyphs.Check + " ready"
+	}
+	return fmt.Sprintf("%s blocked: %s", glyphs.Cross, strings.Join(r.Blockers, ", "))
+}
+
 func (m *PRMarkdownWriter) formatReviewers(pr *bitbucket.PullRequest) string {
 	reviewerMap := make(map[string]string)
 
@@ -54,6 +130,9 @@ func (m *PRMarkdownWriter) formatReviewers(pr *bitbucket.PullRequest) string {
 		status := "pending"
 		if p.Approved {
 			status = "approved"
+			if approvedOn, ok := m.approvals[p.User.Username]; ok {
+				status += " " + FormatRelativeTime(approvedOn)
+			}
 		} else if p.State == "changes_requested" {
 			status = "changes_requested"
 		}
@@ -84,3 +163,61 @@ func (m *PRMarkdownWriter) writeFooter(pr *bitbucket.PullRequest) {
 		fmt.Fprintln(m.w, strings.Join(parts, ", "))
 	}
 }
+
+func buildPRMetadata(pr *bitbucket.PullRequest, keys []string) []metadataField {
+	values := map[string]string{
+		"id":          fmt.Sprintf("%d", pr.ID),
+		"title":       pr.Title,
+		"author":      pr.Author.Username,
+		"state":       pr.State,
+		"source":      pr.Source.Branch.Name,
+		"destination": pr.Destination.Branch.Name,
+		"url":         pr.Links.HTML.Href,
+		"created":     pr.CreatedOn.Format(time.RFC3339),
+		"updated":     pr.UpdatedOn.Format(time.RFC3339),
+	}
+
+	fields := make([]metadataField, 0, len(keys))
+	for _, key := range keys {
+		if value, ok := values[key]; ok {
+			fields = append(fields, metadataField{key, value})
+		}
+	}
+	return fields
+}
+
+func ParseMetaFields(spec string) ([]string, error) {
+	var fields []string
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(spec, ",") {
+		key := strings.ToLower(strings.TrimSpace(part))
+		if key == "" || seen[key] {
+			continue
+		}
+		valid := false
+		for _, known := range PRMetadataFields {
+			if key == known {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return nil, fmt.Errorf("invalid metadata field: %s (valid fields: %s)", key, strings.Join(PRMetadataFields, ", "))
+		}
+		seen[key] = true
+		fields = append(fields, key)
+	}
+	return fields, nil
+}
+
+func (m *PRMarkdownWriter) writeFrontmatter(fields []metadataField) {
+	fmt.Fprintln(m.w, "---")
+	for _, f := range fields {
+		// JSON strings are valid YAML scalars; json escapes control characters
+		// but passes bidi overrides through, hence SanitizeLine first.
+		quoted, _ := json.Marshal(SanitizeLine(f.value))
+		fmt.Fprintf(m.w, "%s: %s\n", f.key, quoted)
+	}
+	fmt.Fprintln(m.w, "---")
+	fmt.Fprintln(m.w)
+}
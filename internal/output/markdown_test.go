@@ -0,0 +1,140 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kabilan108/atlas/internal/bitbucket"
+)
+
+func testPR(t *testing.T) *bitbucket.PullRequest {
+	t.Helper()
+	var pr bitbucket.PullRequest
+	err := json.Unmarshal([]byte(`{
+		"id": 7,
+		"title": "PROJ-1: fix \"quotes\" and colons",
+		"state": "OPEN",
+		"author": {"username": "alice"},
+		"source": {"branch": {"name": "feature/x"}},
+		"destination": {"branch": {"name": "main"}},
+		"links": {"html": {"href": "https://bitbucket.org/ws/repo/pull-requests/7"}},
+		"created_on": "2024-01-02T03:04:05Z",
+		"updated_on": "2024-01-03T03:04:05Z"
+	}`), &pr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &pr
+}
+
+func TestWritePRFrontmatter(t *testing.T) {
+	var buf bytes.Buffer
+	m := NewPRMarkdownWriter(&buf)
+	m.SetFrontmatter(true)
+	if err := m.WritePR(testPR(t)); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `---
+id: "7"
+title: "PROJ-1: fix \"quotes\" and colons"
+author: "alice"
+state: "OPEN"
+source: "feature/x"
+destination: "main"
+url: "https://bitbucket.org/ws/repo/pull-requests/7"
+created: "2024-01-02T03:04:05Z"
+updated: "2024-01-03T03:04:05Z"
+---
+
+# PR #7:`
+	if got := buf.String(); !strings.HasPrefix(got, want) {
+		t.Errorf("output =\n%s\nwant prefix\n%s", got, want)
+	}
+}
+
+func TestWritePRWithoutFrontmatter(t *testing.T) {
+	var buf bytes.Buffer
+	if err := NewPRMarkdownWriter(&buf).WritePR(testPR(t)); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); !strings.HasPrefix(got, "# PR #7:") {
+		t.Errorf("output starts %q, want the heading first", got[:min(len(got), 20)])
+	}
+}
+
+func TestParseMetaFields(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    []string
+		wantErr bool
+	}{
+		{"id,title", []string{"id", "title"}, false},
+		{"url, ID ,url", []string{"url", "id"}, false},
+		{"state,,author", []string{"state", "author"}, false},
+		{"", nil, false},
+		{"id,labels", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := ParseMetaFields(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseMetaFields(%q) err = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseMetaFields(%q) = %v, want %v", tt.spec, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWritePRMetaFieldsOrder(t *testing.T) {
+	var buf bytes.Buffer
+	m := NewPRMarkdownWriter(&buf)
+	m.SetFrontmatter(true)
+	m.SetMetaFields([]string{"url", "id"})
+	if err := m.WritePR(testPR(t)); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "---\nurl: \"https://bitbucket.org/ws/repo/pull-requests/7\"\nid: \"7\"\n---\n\n"
+	if got := buf.String(); !strings.HasPrefix(got, want) {
+		t.Errorf("output =\n%s\nwant prefix\n%s", got, want)
+	}
+}
+
+func TestWritePRApprovalTimes(t *testing.T) {
+	tests := []struct {
+		name      string
+		approvals map[string]time.Time
+		want      string
+	}{
+		{"without approval times", nil, "@bob (approved)"},
+		{"with approval time", map[string]time.Time{"bob": time.Now().Add(-3 * time.Hour)}, "@bob (approved 3 hours ago)"},
+		{"other reviewer's time ignored", map[string]time.Time{"carol": time.Now().Add(-3 * time.Hour)}, "@bob (approved)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pr := testPR(t)
+			pr.Participants = []bitbucket.Participant{
+				{User: bitbucket.User{Username: "bob"}, Role: "REVIEWER", Approved: true},
+			}
+
+			var buf bytes.Buffer
+			m := NewPRMarkdownWriter(&buf)
+			m.SetApprovals(tt.approvals)
+			if err := m.WritePR(pr); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); !strings.Contains(got, tt.want) {
+				t.Errorf("output missing %q:\n%s", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package output
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"os/exec"
+
+	"golang.org/x/term"
+)
+
+const defaultPager = "less -R"
+
+// Pager buffers human output destined for a terminal and, if it turns out
+// taller than the screen, shows it through $PAGER. When out is not a terminal
+// or paging is disabled, writes go straight to out.
+type Pager struct {
+	out     *os.File
+	buf     bytes.Buffer
+	enabled bool
+}
+
+func NewPager(out *os.File, enabled bool) *Pager {
+	return &Pager{out: out, enabled: enabled && IsTerminal(out)}
+}
+
+func (p *Pager) Writer() io.Writer {
+	if !p.enabled {
+		return p.out
+	}
+	return &p.buf
+}
+
+// Close flushes buffered output, through the pager when it does not fit on
+// screen. If the pager cannot be started the output is written directly.
+func (p *Pager) Close() error {
+	if !p.enabled {
+		return nil
+	}
+
+	_, height, err := term.GetSize(int(p.out.Fd()))
+	if err != nil || bytes.Count(p.buf.Bytes(), []byte("\n")) < height {
+		_, err := p.buf.WriteTo(p.out)
+		return err
+	}
+
+	pager := os.Getenv("PAGER")
+	if pager == "" {
+		pager = defaultPager
+	}
+
+	cmd := exec.Command("sh", "-c", pager)
+	cmd.Stdin = bytes.NewReader(p.buf.Bytes())
+	cmd.Stdout = p.out
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		LogVerbose("Pager %q failed: %s", pager, err)
+		_, err := p.buf.WriteTo(p.out)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package output
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPagerPassesThroughWithoutTerminal(t *testing.T) {
+	for _, enabled := range []bool{true, false} {
+		t.Run(fmt.Sprintf("enabled=%v", enabled), func(t *testing.T) {
+			t.Setenv("PAGER", "false")
+			path := filepath.Join(t.TempDir(), "out")
+			f, err := os.Create(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+
+			p := NewPager(f, enabled)
+			if p.Writer() != f {
+				t.Error("writer is buffered although output is not a terminal")
+			}
+			fmt.Fprint(p.Writer(), "line 1\nline 2\n")
+			if err := p.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != "line 1\nline 2\n" {
+				t.Errorf("output = %q", data)
+			}
+		})
+	}
+}
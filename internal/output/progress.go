@@ -0,0 +1,41 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"sync"
+)
+
+type Progress struct {
+	mu      sync.Mutex
+	w       io.Writer
+	label   string
+	enabled bool
+	written bool
+}
+
+func NewProgress(w io.Writer, label string) *Progress {
+	enabled := !defaultLogger.IsQuiet() && IsTerminal(w)
+	return &Progress{w: w, label: label, enabled: enabled}
+}
+
+func (p *Progress) Update(done, total int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.enabled {
+		return
+	}
+	fmt.Fprintf(p.w, "\r\033[Kprocessed %d/%d %s", done, total, p.label)
+	p.written = true
+}
+
+func (p *Progress) Done() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.written {
+		fmt.Fprint(p.w, "\r\033[K")
+		p.written = false
+	}
+}
@@ -0,0 +1,42 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestProgress(t *testing.T) {
+	tests := []struct {
+		name    string
+		enabled bool
+		want    string
+	}{
+		{"terminal", true, "\r\033[Kprocessed 1/2 repos\r\033[Kprocessed 2/2 repos\r\033[K"},
+		{"not a terminal", false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			p := &Progress{w: &buf, label: "repos", enabled: tt.enabled}
+			p.Update(1, 2)
+			p.Update(2, 2)
+			p.Done()
+			p.Done()
+
+			if got := buf.String(); got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewProgressDisabledForPipes(t *testing.T) {
+	var buf bytes.Buffer
+	p := NewProgress(&buf, "repos")
+	p.Update(1, 1)
+	p.Done()
+	if buf.Len() != 0 {
+		t.Errorf("progress written to a non-terminal: %q", buf.String())
+	}
+}
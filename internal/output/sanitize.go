@@ -0,0 +1,81 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// SanitizeText neutralizes characters in user-authored text that can corrupt
+// a terminal or hide content from whoever reads the output. C0 and C1 control
+// characters other than newline and tab are dropped (ESC would otherwise start
+// terminal escape sequences). Bidi embedding, override and isolate controls
+// are replaced with a visible <U+XXXX> marker, so reordered "Trojan Source"
+// text reads in logical order and the trick stays noticeable.
+func SanitizeText(s string) string {
+	return sanitize(s, false)
+}
+
+// SanitizeLine is SanitizeText for single-line fields such as titles, URLs
+// and attribute values: newlines and tabs also become spaces, and the result
+// is NFC-normalized so visually identical titles compare equal.
+func SanitizeLine(s string) string {
+	return norm.NFC.String(sanitize(s, true))
+}
+
+func sanitize(s string, singleLine bool) string {
+	if !needsSanitizing(s, singleLine) {
+		return s
+	}
+
+	var sb strings.Builder
+	sb.Grow(len(s))
+	for _, r := range s {
+		switch {
+		case r == '\n' || r == '\t':
+			if singleLine {
+				sb.WriteByte(' ')
+			} else {
+				sb.WriteRune(r)
+			}
+		case isControl(r):
+		case isBidiControl(r):
+			fmt.Fprintf(&sb, "<U+%04X>", r)
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+func needsSanitizing(s string, singleLine bool) bool {
+	for i := 0; i < len(s); i++ {
+		b := s[i]
+		if b < 0x20 || b == 0x7f {
+			if singleLine || (b != '\n' && b != '\t') {
+				return true
+			}
+		}
+		if b >= utf8.RuneSelf {
+			// Only multi-byte runes can be C1 or bidi controls; decode lazily.
+			r, _ := utf8.DecodeRuneInString(s[i:])
+			if isControl(r) || isBidiControl(r) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isControl(r rune) bool {
+	return r < 0x20 || (r >= 0x7f && r <= 0x9f)
+}
+
+// isBidiControl matches the explicit directional formatting characters:
+// LRE, RLE, PDF, LRO, RLO (U+202A–U+202E) and LRI, RLI, FSI, PDI
+// (U+2066–U+2069).
+func isBidiControl(r rune) bool {
+	return (r >= 0x202a && r <= 0x202e) || (r >= 0x2066 && r <= 0x2069)
+}
@@ -0,0 +1,92 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/kabilan108/atlas/internal/bitbucket"
+)
+
+func TestSanitize(t *testing.T) {
+	tests := []struct {
+		name     string
+		in       string
+		wantText string
+		wantLine string
+	}{
+		{"clean text untouched", "hello\n\tworld", "hello\n\tworld", "hello  world"},
+		{"escape sequence dropped", "red \x1b[31mtext\x1b[0m", "red [31mtext[0m", "red [31mtext[0m"},
+		{"c1 control dropped", "a\u0085b\u009bc", "abc", "abc"},
+		{"bidi override made visible", "admin\u202e\u2066 // x", "admin<U+202E><U+2066> // x", "admin<U+202E><U+2066> // x"},
+		{"carriage return dropped", "line\r\n", "line\n", "line "},
+		// "e" + combining acute composes to "é" only for single-line fields.
+		{"nfc on lines only", "cafe\u0301", "cafe\u0301", "caf\u00e9"},
+		{"non-ascii kept", "naïve 日本", "naïve 日本", "naïve 日本"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SanitizeText(tt.in); got != tt.wantText {
+				t.Errorf("SanitizeText(%q) = %q, want %q", tt.in, got, tt.wantText)
+			}
+			if got := SanitizeLine(tt.in); got != tt.wantLine {
+				t.Errorf("SanitizeLine(%q) = %q, want %q", tt.in, got, tt.wantLine)
+			}
+		})
+	}
+}
+
+func TestWriteLLMBundleSanitizes(t *testing.T) {
+	docs := []LLMDocument{{Title: "evil\x1b]0;title\x07", Source: "src\nnext", Content: "body\u202e"}}
+
+	var sb strings.Builder
+	if err := WriteLLMBundle(&sb, docs); err != nil {
+		t.Fatal(err)
+	}
+	want := "<documents>\n<index>\n1. evil]0;title (src next)\n</index>\n" +
+		"<document index=\"1\">\n<source>src next</source>\n<content>\nbody<U+202E>\n</content>\n</document>\n</documents>\n"
+	if got := sb.String(); got != want {
+		t.Errorf("got %q\nwant %q", got, want)
+	}
+}
+
+func TestWriteTasksSanitizes(t *testing.T) {
+	tests := []struct {
+		name    string
+		content bitbucket.Content
+		want    string
+	}{
+		{"raw escape sequence", bitbucket.Content{Raw: "fix \x1b[2Jtypo"}, "- [ ] fix [2Jtypo\n"},
+		{"raw entity hides bidi", bitbucket.Content{Raw: "check &#x202E;this"}, "- [ ] check <U+202E>this\n"},
+		{"html bidi", bitbucket.Content{HTML: "<p>rename\u2066 var</p>"}, "- [ ] rename<U+2066> var\n"},
+		{"multi-line raw", bitbucket.Content{Raw: "first\r\nsecond"}, "- [ ] first second\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sb strings.Builder
+			if err := NewTaskWriter(&sb).WriteTasks([]bitbucket.Task{{Content: tt.content}}); err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.TrimPrefix(sb.String(), "## Tasks\n\n"); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWritePRSanitizesBranches(t *testing.T) {
+	pr := testPR(t)
+	pr.Source.Branch.Name = "feature/\u202eevil"
+	pr.Destination.Branch.Name = "main\x1b[0m"
+
+	var buf bytes.Buffer
+	if err := NewPRMarkdownWriter(&buf).WritePR(pr); err != nil {
+		t.Fatal(err)
+	}
+	want := "**Branch**: feature/<U+202E>evil " + CurrentGlyphs().Arrow + " main[0m\n"
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("output missing %q:\n%s", want, buf.String())
+	}
+}
@@ -4,21 +4,54 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"sync/atomic"
 	"text/tabwriter"
 	"time"
 )
 
+const (
+	TableStyleAligned  = "aligned"
+	TableStyleMarkdown = "markdown"
+)
+
+var tableStyle atomic.Value
+
+// SetTableStyle selects how every TableWriter renders: space-aligned columns
+// or a GitHub-style pipe table for pasting into markdown.
+func SetTableStyle(style string) error {
+	switch style {
+	case TableStyleAligned, TableStyleMarkdown:
+		tableStyle.Store(style)
+		return nil
+	}
+	return fmt.Errorf("invalid table style %q (valid: %s, %s)", style, TableStyleAligned, TableStyleMarkdown)
+}
+
+func currentTableStyle() string {
+	if style, ok := tableStyle.Load().(string); ok {
+		return style
+	}
+	return TableStyleAligned
+}
+
 type TableWriter struct {
-	w       *tabwriter.Writer
-	headers []string
+	out      io.Writer
+	w        *tabwriter.Writer
+	headers  []string
+	markdown bool
+	rows     [][]string
 }
 
 func NewTableWriter(out io.Writer, headers ...string) *TableWriter {
 	tw := &TableWriter{
-		w:       tabwriter.NewWriter(out, 0, 0, 2, ' ', 0),
-		headers: headers,
+		out:      out,
+		w:        tabwriter.NewWriter(out, 0, 0, 2, ' ', 0),
+		headers:  headers,
+		markdown: currentTableStyle() == TableStyleMarkdown,
+	}
+	if !tw.markdown {
+		tw.writeRow(headers...)
 	}
-	tw.writeRow(headers...)
 	return tw
 }
 
@@ -26,14 +59,55 @@ func (t *TableWriter) writeRow(cols ...string) {
 	fmt.Fprintln(t.w, strings.Join(cols, "\t"))
 }
 
+// AddRow sanitizes each cell: rows carry titles and names from the API, and a
+// stray control or bidi character would scramble the rest of the table.
 func (t *TableWriter) AddRow(cols ...string) {
+	sanitized := make([]string, len(cols))
+	for i, col := range cols {
+		sanitized[i] = SanitizeLine(col)
+	}
+	cols = sanitized
+	if t.markdown {
+		t.rows = append(t.rows, cols)
+		return
+	}
 	t.writeRow(cols...)
 }
 
 func (t *TableWriter) Flush() error {
+	if t.markdown {
+		return t.writeMarkdown()
+	}
 	return t.w.Flush()
 }
 
+func (t *TableWriter) writeMarkdown() error {
+	var sb strings.Builder
+	writeMarkdownRow(&sb, t.headers)
+
+	separator := make([]string, len(t.headers))
+	for i := range separator {
+		separator[i] = "---"
+	}
+	writeMarkdownRow(&sb, separator)
+
+	for _, row := range t.rows {
+		writeMarkdownRow(&sb, row)
+	}
+
+	_, err := io.WriteString(t.out, sb.String())
+	return err
+}
+
+func writeMarkdownRow(sb *strings.Builder, cols []string) {
+	escaped := make([]string, len(cols))
+	for i, col := range cols {
+		col = strings.ReplaceAll(col, "|", "\\|")
+		escaped[i] = strings.ReplaceAll(col, "\n", " ")
+	}
+	fmt.Fprintf(sb, "| %s |\n", strings.Join(escaped, " | "))
+}
+
 func FormatRelativeTime(t time.Time) string {
 	now := time.Now()
 	diff := now.Sub(t)
@@ -70,6 +144,19 @@ func FormatRelativeTime(t time.Time) string {
 	}
 }
 
+// FormatDuration renders a span compactly for summary tables: minutes under
+// an hour, hours under two days, days beyond that.
+func FormatDuration(d time.Duration) string {
+	switch {
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%.1fh", d.Hours())
+	default:
+		return fmt.Sprintf("%.1fd", d.Hours()/24)
+	}
+}
+
 func Truncate(s string, maxLen int) string {
 	if len(s) <= maxLen {
 		return s
@@ -79,3 +166,16 @@ func Truncate(s string, maxLen int) string {
 	}
 	return s[:maxLen-3] + "..."
 }
+
+func FormatSize(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
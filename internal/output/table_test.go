@@ -0,0 +1,86 @@
+package output
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatSize(t *testing.T) {
+	tests := []struct {
+		bytes int64
+		want  string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1024, "1.0 KiB"},
+		{1536, "1.5 KiB"},
+		{5 * 1024 * 1024, "5.0 MiB"},
+		{3 << 30, "3.0 GiB"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatSize(tt.bytes); got != tt.want {
+			t.Errorf("FormatSize(%d) = %q, want %q", tt.bytes, got, tt.want)
+		}
+	}
+}
+
+func TestTableStyles(t *testing.T) {
+	t.Cleanup(func() { SetTableStyle(TableStyleAligned) })
+
+	tests := []struct {
+		style string
+		want  string
+	}{
+		{TableStyleAligned, "ID  TITLE\n1   a | b\n22  c\n"},
+		{TableStyleMarkdown, "| ID | TITLE |\n| --- | --- |\n| 1 | a \\| b |\n| 22 | c |\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.style, func(t *testing.T) {
+			if err := SetTableStyle(tt.style); err != nil {
+				t.Fatal(err)
+			}
+
+			var sb strings.Builder
+			tw := NewTableWriter(&sb, "ID", "TITLE")
+			tw.AddRow("1", "a | b")
+			tw.AddRow("22", "c")
+			if err := tw.Flush(); err != nil {
+				t.Fatal(err)
+			}
+			if got := sb.String(); got != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetTableStyleRejectsUnknown(t *testing.T) {
+	if err := SetTableStyle("csv"); err == nil {
+		t.Error("SetTableStyle(csv) succeeded")
+	}
+	if got := currentTableStyle(); got != TableStyleAligned {
+		t.Errorf("style after invalid set = %q, want %q", got, TableStyleAligned)
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "0m"},
+		{45 * time.Minute, "45m"},
+		{90 * time.Minute, "1.5h"},
+		{47 * time.Hour, "47.0h"},
+		{60 * time.Hour, "2.5d"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatDuration(tt.d); got != tt.want {
+			t.Errorf("FormatDuration(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
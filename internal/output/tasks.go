@@ -2,18 +2,21 @@ package output
 
 import (
 	"fmt"
+	"html"
 	"io"
 	"strings"
 
+	md "github.com/JohannesKaufmann/html-to-markdown"
 	"github.com/kabilan108/atlas/internal/bitbucket"
 )
 
 type TaskWriter struct {
-	w io.Writer
+	w         io.Writer
+	converter *md.Converter
 }
 
 func NewTaskWriter(w io.Writer) *TaskWriter {
-	return &TaskWriter{w: w}
+	return &TaskWriter{w: w, converter: NewMarkdownConverter()}
 }
 
 func (tw *TaskWriter) WriteTasks(tasks []bitbucket.Task) error {
@@ -37,11 +40,15 @@ func (tw *TaskWriter) WriteTasks(tasks []bitbucket.Task) error {
 }
 
 func (tw *TaskWriter) formatContent(content bitbucket.Content) string {
-	text := content.Raw
+	var text string
+	if content.HTML != "" {
+		if converted, err := tw.converter.ConvertString(content.HTML); err == nil {
+			text = converted
+		}
+	}
 	if text == "" {
-		text = content.HTML
+		// Some responses carry HTML entities even in the raw field.
+		text = html.UnescapeString(content.Raw)
 	}
-	text = strings.TrimSpace(text)
-	text = strings.ReplaceAll(text, "\n", " ")
-	return text
+	return SanitizeLine(strings.TrimSpace(text))
 }
@@ -0,0 +1,44 @@
+package output
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kabilan108/atlas/internal/bitbucket"
+)
+
+func TestWriteTasksDecodesEntities(t *testing.T) {
+	tests := []struct {
+		name string
+		task bitbucket.Task
+		want string
+	}{
+		{
+			name: "raw entities decoded",
+			task: bitbucket.Task{State: "UNRESOLVED", Content: bitbucket.Content{Raw: "check a &lt; b &amp;&amp; c &quot;ok&quot;"}},
+			want: `- [ ] check a < b && c "ok"`,
+		},
+		{
+			name: "html converted to markdown",
+			task: bitbucket.Task{State: "RESOLVED", Content: bitbucket.Content{Raw: "ignored", HTML: "<p>use <code>x &gt; 0</code></p>"}},
+			want: "- [x] use `x > 0`",
+		},
+		{
+			name: "multi-line raw flattened",
+			task: bitbucket.Task{State: "UNRESOLVED", Content: bitbucket.Content{Raw: "first\nsecond\n"}},
+			want: "- [ ] first second",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sb strings.Builder
+			if err := NewTaskWriter(&sb).WriteTasks([]bitbucket.Task{tt.task}); err != nil {
+				t.Fatal(err)
+			}
+			if got := sb.String(); got != "## Tasks\n\n"+tt.want+"\n" {
+				t.Errorf("got %q, want task line %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package output
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"testing"
+)
+
+func TestIsTerminal(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	tests := []struct {
+		name string
+		w    io.Writer
+	}{
+		{"buffer", &bytes.Buffer{}},
+		{"pipe", w},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if IsTerminal(tt.w) {
+				t.Errorf("IsTerminal(%s) = true, want false", tt.name)
+			}
+		})
+	}
+}